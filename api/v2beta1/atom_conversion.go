@@ -26,7 +26,7 @@ package v2beta1
 
 import (
 	"log"
-	"net/url"
+	"maps"
 	"strconv"
 	"time"
 
@@ -37,6 +37,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
+// baseURLAnnotation keeps the baseUrl of the Hub version, which v2beta1 has no field for.
+// The baseUrl is kept as long as spec.general is unchanged, so later changes of the base URL
+// of the operator do not move existing Atoms. The annotation is never stored in the Hub version.
+const baseURLAnnotation = "pdok.nl/base-url"
+
 // ConvertTo converts this Atom (v2beta1) to the Hub version (v3).
 func (a *Atom) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*pdoknlv3.Atom)
@@ -60,10 +65,14 @@ func (a *Atom) ToV3(dst *pdoknlv3.Atom) error {
 		}
 	}
 
-	baseURL, err := createBaseURL(pdoknlv3.GetBaseURL(), a.Spec.General)
+	baseURL, err := getBaseURL(a)
 	if err != nil {
 		return err
 	}
+	if _, ok := dst.Annotations[baseURLAnnotation]; ok {
+		dst.Annotations = maps.Clone(dst.Annotations)
+		delete(dst.Annotations, baseURLAnnotation)
+	}
 
 	// Service
 	dst.Spec.Service = pdoknlv3.Service{
@@ -204,29 +213,23 @@ func (a *Atom) ConvertFrom(srcRaw conversion.Hub) error {
 
 	// ObjectMeta
 	a.ObjectMeta = src.ObjectMeta
-
-	// General
-	a.Spec.General = General{
-		Dataset:      src.Labels["dataset"],
-		DatasetOwner: src.Labels["dataset-owner"],
-		DataVersion:  nil,
-	}
-
-	serviceVersion, ok := src.Labels["service-version"]
-	if ok {
-		a.Spec.General.ServiceVersion = &serviceVersion
+	a.Annotations = maps.Clone(a.Annotations)
+	delete(a.Annotations, baseURLAnnotation)
+	if src.Spec.Service.BaseURL.URL != nil {
+		if a.Annotations == nil {
+			a.Annotations = make(map[string]string)
+		}
+		a.Annotations[baseURLAnnotation] = src.Spec.Service.BaseURL.String()
 	}
 
-	theme, ok := src.Labels["theme"]
-	if ok {
-		a.Spec.General.Theme = &theme
-	}
+	// General
+	a.Spec.General = getGeneral(src.Labels)
 
 	// Service
 	a.Spec.Service = AtomService{
 		Title:    src.Spec.Service.Title,
 		Subtitle: src.Spec.Service.Subtitle,
-		Rights:   src.Spec.Service.Rights,
+		Rights:   src.Spec.Service.GetRights(nil),
 		Author: Author{
 			Name:  "PDOK Beheer",
 			Email: "beheerPDOK@kadaster.nl",
@@ -257,7 +260,7 @@ func (a *Atom) ConvertFrom(srcRaw conversion.Hub) error {
 
 		if len(srcDatasetFeed.Entries) > 0 {
 			// We can assume all entries have the same bbox, so we take the first one
-			firstBbox := srcDatasetFeed.Entries[0].Polygon.BBox
+			firstBbox := srcDatasetFeed.Entries[0].Polygon.GetBBox()
 			dstDataset.Bbox = Bbox{
 				Minx: GetStringAsFloat32(firstBbox.MinX),
 				Miny: GetStringAsFloat32(firstBbox.MinY),
@@ -322,22 +325,55 @@ func (a *Atom) ConvertFrom(srcRaw conversion.Hub) error {
 	return nil
 }
 
-func createBaseURL(host string, general General) (*smoothoperatormodel.URL, error) {
-	baseURL, err := url.Parse(host)
-	if err != nil {
-		return nil, err
+// getGeneral returns the general settings of the Hub version, which are kept in its labels
+func getGeneral(labels map[string]string) General {
+	general := General{
+		Dataset:      labels["dataset"],
+		DatasetOwner: labels["dataset-owner"],
+		DataVersion:  nil,
 	}
-	baseURL = baseURL.JoinPath(general.DatasetOwner, general.Dataset)
-	if general.Theme != nil {
-		baseURL = baseURL.JoinPath(*general.Theme)
+
+	serviceVersion, ok := labels["service-version"]
+	if ok {
+		general.ServiceVersion = &serviceVersion
+	}
+
+	theme, ok := labels["theme"]
+	if ok {
+		general.Theme = &theme
 	}
-	baseURL = baseURL.JoinPath("atom")
+	return general
+}
 
-	if general.ServiceVersion != nil {
-		baseURL = baseURL.JoinPath(*general.ServiceVersion)
+// getBaseURL returns the baseUrl the Atom had as Hub version when spec.general is unchanged,
+// or else derives its path from spec.general. The conversion cannot read the operator config,
+// so the defaulting webhook prefixes the path with the base URL of the operator.
+func getBaseURL(a *Atom) (*smoothoperatormodel.URL, error) {
+	if baseURL, ok := a.Annotations[baseURLAnnotation]; ok && !generalChanged(getGeneral(a.Labels), a.Spec.General) {
+		parsed, err := smoothoperatormodel.ParseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		return &smoothoperatormodel.URL{URL: parsed}, nil
 	}
+	return createBaseURL("/", a.Spec.General)
+}
 
-	return &smoothoperatormodel.URL{URL: baseURL}, nil
+// generalChanged reports whether the general settings that make up the baseUrl differ
+func generalChanged(old, general General) bool {
+	oldURL, err := createBaseURL("", old)
+	if err != nil {
+		return true
+	}
+	newURL, err := createBaseURL("", general)
+	if err != nil {
+		return true
+	}
+	return oldURL.String() != newURL.String()
+}
+
+func createBaseURL(host string, general General) (*smoothoperatormodel.URL, error) {
+	return pdoknlv3.CreateBaseURL(host, general.DatasetOwner, general.Dataset, general.Theme, general.ServiceVersion)
 }
 
 func GetInt32Pointer(value int32) *int32 {
@@ -1,6 +1,7 @@
 package v2beta1
 
 import (
+	"net/url"
 	"sync/atomic"
 	"testing"
 
@@ -14,7 +15,6 @@ func TestAtom_ConvertTo(t *testing.T) {
 	convertFromAtom := getTestAtomV2()
 	convertToAtom := &pdoknlv3.Atom{}
 	dstRaw := conversion.Hub(convertToAtom)
-	pdoknlv3.SetBaseURL("https://test.com/test")
 	err := convertFromAtom.ConvertTo(dstRaw)
 	if err != nil {
 		t.Errorf("ConvertTo() error = %v", err)
@@ -52,6 +52,28 @@ func TestAtom_ConvertTo(t *testing.T) {
 
 }
 
+func TestAtom_ConvertFrom_License(t *testing.T) {
+	hub := &pdoknlv3.Atom{}
+	if err := getTestAtomV2().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	licenseURI, err := url.Parse("https://creativecommons.org/licenses/by/4.0/deed.nl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attribution := "Bron: PDOK"
+	hub.Spec.Service.Rights = ""
+	hub.Spec.Service.License = &pdoknlv3.License{URI: smoothoperatormodel.URL{URL: licenseURI}, Label: "CC BY 4.0", Attribution: &attribution}
+
+	spoke := &Atom{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if want := "CC BY 4.0. Bron: PDOK"; spoke.Spec.Service.Rights != want {
+		t.Errorf("ConvertFrom() error = Rights: %v, %v", want, spoke.Spec.Service.Rights)
+	}
+}
+
 var testTheme = "TEST_THEME"
 var TestServiceVersion = "v1_0"
 var TestDataVersion = "v1.0"
@@ -175,3 +197,78 @@ func getFilledAtomv3() *pdoknlv3.Atom {
 		},
 	}
 }
+
+func TestAtom_ConvertBaseURL(t *testing.T) {
+	hub := &pdoknlv3.Atom{}
+	if err := getTestAtomV2().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	// The defaulting webhook completes the derived path with the base URL of the operator
+	if want, got := "/test_datasetowner/test_dataset/"+testTheme+"/atom/"+TestServiceVersion, hub.Spec.Service.BaseURL.String(); got != want {
+		t.Errorf("ConvertTo() error = BaseURL: %v, %v", want, got)
+	}
+	baseURL := "https://test.com/test" + hub.Spec.Service.BaseURL.String()
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub.Spec.Service.BaseURL = smoothoperatormodel.URL{URL: parsed}
+
+	spoke := &Atom{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if got := spoke.Annotations[baseURLAnnotation]; got != baseURL {
+		t.Errorf("ConvertFrom() error = annotation %s: %v, %v", baseURLAnnotation, baseURL, got)
+	}
+	if _, ok := hub.Annotations[baseURLAnnotation]; ok {
+		t.Errorf("ConvertFrom() error = the annotations of the hub should not change")
+	}
+
+	// An existing Atom keeps its baseUrl while spec.general is unchanged
+	converted := &pdoknlv3.Atom{}
+	if err := spoke.ConvertTo(converted); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got := converted.Spec.Service.BaseURL.String(); got != baseURL {
+		t.Errorf("ConvertTo() error = BaseURL: %v, %v", baseURL, got)
+	}
+	if _, ok := converted.Annotations[baseURLAnnotation]; ok {
+		t.Errorf("ConvertTo() error = annotation %s should not be stored", baseURLAnnotation)
+	}
+}
+
+func TestAtom_ConvertBaseURLChangedGeneral(t *testing.T) {
+	parsed, err := url.Parse("https://test.com/test/test_datasetowner/test_dataset/" + testTheme + "/atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := &pdoknlv3.Atom{}
+	if err := getTestAtomV2().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	hub.Labels = map[string]string{"dataset-owner": "test_datasetowner", "dataset": "test_dataset", "theme": testTheme}
+	hub.Annotations = map[string]string{baseURLAnnotation: "https://stale.com"}
+	hub.Spec.Service.BaseURL = smoothoperatormodel.URL{URL: parsed}
+
+	spoke := &Atom{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if got := spoke.Annotations[baseURLAnnotation]; got != parsed.String() {
+		t.Errorf("ConvertFrom() error = annotation %s: %v, %v", baseURLAnnotation, parsed.String(), got)
+	}
+
+	// A changed dataset derives the path of the baseUrl again
+	spoke.Spec.General.Dataset = "other_dataset"
+	converted := &pdoknlv3.Atom{}
+	if err := spoke.ConvertTo(converted); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if want, got := "/test_datasetowner/other_dataset/"+testTheme+"/atom", converted.Spec.Service.BaseURL.String(); got != want {
+		t.Errorf("ConvertTo() error = BaseURL: %v, %v", want, got)
+	}
+	if _, ok := converted.Annotations[baseURLAnnotation]; ok {
+		t.Errorf("ConvertTo() error = annotation %s should not be stored", baseURLAnnotation)
+	}
+}
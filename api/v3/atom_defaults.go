@@ -0,0 +1,147 @@
+package v3
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ownerIDLabel        = "pdok.nl/owner-id"
+	datasetIDLabel      = "pdok.nl/dataset-id"
+	tagLabel            = "pdok.nl/tag"
+	serviceVersionLabel = "pdok.nl/service-version"
+)
+
+// Default fills in the fields that can be derived, so stored Atoms are explicit.
+// The baseUrl is derived from baseURL, the base URL of the operator for the namespace of the Atom.
+// URLs are only normalised when normaliseURLs is set, because changing the URLs of an existing Atom is not allowed.
+// The OwnerInfo is only consulted if k8s client is available.
+func (atom *Atom) Default(c client.Client, baseURL string, normaliseURLs bool) {
+	if atom.Spec.Service.BaseURL.URL == nil {
+		atom.Spec.Service.BaseURL = deriveBaseURL(baseURL, atom.Labels)
+	} else if !atom.Spec.Service.BaseURL.IsAbs() {
+		// Atoms converted from v2beta1 get a baseUrl without host, because the conversion cannot read the operator config
+		atom.Spec.Service.BaseURL = completeBaseURL(baseURL, atom.Spec.Service.BaseURL)
+	}
+
+	if normaliseURLs {
+		atom.Spec.Service.BaseURL = normaliseURL(atom.Spec.Service.BaseURL)
+		for i := range atom.Spec.IngressRouteURLs {
+			atom.Spec.IngressRouteURLs[i].URL = normaliseURL(atom.Spec.IngressRouteURLs[i].URL)
+		}
+	}
+
+	if c != nil {
+		defaultSpatialDatasetIdentifierNamespaces(c, atom)
+	}
+
+	defaultDownloadLinkRels(atom)
+	defaultPolygonBBoxes(atom)
+}
+
+// deriveBaseURL creates the base URL from the base URL of the operator and the labels, if all required labels are present
+func deriveBaseURL(baseURL string, labels map[string]string) smoothoperatormodel.URL {
+	owner, dataset := labels[ownerIDLabel], labels[datasetIDLabel]
+	if baseURL == "" || owner == "" || dataset == "" {
+		return smoothoperatormodel.URL{}
+	}
+
+	var theme, serviceVersion *string
+	if tag, ok := labels[tagLabel]; ok && tag != "" {
+		theme = &tag
+	}
+	if version, ok := labels[serviceVersionLabel]; ok && version != "" {
+		serviceVersion = &version
+	}
+
+	derived, err := CreateBaseURL(baseURL, owner, dataset, theme, serviceVersion)
+	if err != nil {
+		return smoothoperatormodel.URL{}
+	}
+	return *derived
+}
+
+// completeBaseURL prefixes the path of the baseUrl with the base URL of the operator, if there is one
+func completeBaseURL(baseURL string, u smoothoperatormodel.URL) smoothoperatormodel.URL {
+	if baseURL == "" {
+		return u
+	}
+	completed, err := url.Parse(baseURL)
+	if err != nil {
+		return u
+	}
+	return smoothoperatormodel.URL{URL: completed.JoinPath(u.Path)}
+}
+
+// normaliseURL lowercases the scheme and host and cleans the path, without a trailing slash or index.xml
+func normaliseURL(u smoothoperatormodel.URL) smoothoperatormodel.URL {
+	if u.URL == nil {
+		return u
+	}
+
+	normalised := *u.URL
+	normalised.Scheme = strings.ToLower(normalised.Scheme)
+	normalised.Host = strings.ToLower(normalised.Host)
+	normalised.Path = path.Clean("/" + normalised.Path)
+	normalised.Path = strings.TrimSuffix(strings.TrimSuffix(normalised.Path, "/index.xml"), "/")
+	normalised.RawPath = ""
+
+	return smoothoperatormodel.URL{URL: &normalised}
+}
+
+// defaultSpatialDatasetIdentifierNamespaces uses the provider site of the owner as namespace for spatial dataset identifier codes
+func defaultSpatialDatasetIdentifierNamespaces(c client.Client, atom *Atom) {
+	ownerInfo := &smoothoperatorv1.OwnerInfo{}
+	objectKey := client.ObjectKey{
+		Namespace: atom.Namespace,
+		Name:      atom.Spec.Service.OwnerInfoRef,
+	}
+	if err := c.Get(context.Background(), objectKey, ownerInfo); err != nil {
+		// A missing OwnerInfo is reported by the validation
+		return
+	}
+	if ownerInfo.Spec.ProviderSite == nil || ownerInfo.Spec.ProviderSite.Href == "" {
+		return
+	}
+
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		if datasetFeed.SpatialDatasetIdentifierCode != nil && datasetFeed.SpatialDatasetIdentifierNamespace == nil {
+			namespace := ownerInfo.Spec.ProviderSite.Href
+			atom.Spec.Service.DatasetFeeds[i].SpatialDatasetIdentifierNamespace = &namespace
+		}
+	}
+}
+
+// defaultDownloadLinkRels sets the rel that is used in the feed on every download link
+func defaultDownloadLinkRels(atom *Atom) {
+	for i := range atom.Spec.Service.DatasetFeeds {
+		for j := range atom.Spec.Service.DatasetFeeds[i].Entries {
+			entry := &atom.Spec.Service.DatasetFeeds[i].Entries[j]
+
+			// Determine all rels first, the default depends on the number of links without rel
+			rels := make([]string, len(entry.DownloadLinks))
+			for k, downloadLink := range entry.DownloadLinks {
+				rels[k] = entry.GetDownloadLinkRel(downloadLink)
+			}
+			for k := range entry.DownloadLinks {
+				entry.DownloadLinks[k].Rel = &rels[k]
+			}
+		}
+	}
+}
+
+// defaultPolygonBBoxes derives the bbox of polygons that only have a geometry
+func defaultPolygonBBoxes(atom *Atom) {
+	for i := range atom.Spec.Service.DatasetFeeds {
+		for j := range atom.Spec.Service.DatasetFeeds[i].Entries {
+			polygon := &atom.Spec.Service.DatasetFeeds[i].Entries[j].Polygon
+			polygon.BBox = polygon.GetBBox()
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package v3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+)
+
+// Position is an x, y coordinate
+// +kubebuilder:object:generate=false
+type Position [2]float64
+
+// Ring is a closed line string, the first and last position are equal
+// +kubebuilder:object:generate=false
+type Ring []Position
+
+// Geometry is a (multi)polygon, each polygon consists of an exterior ring optionally followed by interior rings (holes)
+// +kubebuilder:object:generate=false
+type Geometry [][]Ring
+
+// ParseGeometry parses a GeoJSON or WKT Polygon or MultiPolygon
+func ParseGeometry(s string) (Geometry, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		return parseGeoJSON(s)
+	}
+	return parseWKT(s)
+}
+
+// IsSimplePolygon returns true if the geometry is a single polygon without holes
+func (g Geometry) IsSimplePolygon() bool {
+	return len(g) == 1 && len(g[0]) == 1
+}
+
+// Validate checks that every ring is closed and that exterior rings are counterclockwise and interior rings clockwise, like the right-hand rule of GeoJSON
+func (g Geometry) Validate() error {
+	for i, polygon := range g {
+		for j, ring := range polygon {
+			name := fmt.Sprintf("ring %d of polygon %d", j+1, i+1)
+			if len(ring) < 4 {
+				return fmt.Errorf("%s has %d positions, at least 4 are required", name, len(ring))
+			}
+			if ring[0] != ring[len(ring)-1] {
+				return fmt.Errorf("%s is not closed, the first and last position should be equal", name)
+			}
+
+			area := ring.signedArea()
+			switch {
+			case area == 0:
+				return fmt.Errorf("%s has no area", name)
+			case j == 0 && area < 0:
+				return fmt.Errorf("%s is an exterior ring and should be counterclockwise", name)
+			case j > 0 && area > 0:
+				return fmt.Errorf("%s is an interior ring and should be clockwise", name)
+			}
+		}
+	}
+	return nil
+}
+
+// BBox returns the bounding box of the exterior rings
+func (g Geometry) BBox() smoothoperatormodel.BBox {
+	e := g.extent()
+	return smoothoperatormodel.BBox{
+		MinX: strconv.FormatFloat(e.minX, 'f', -1, 64),
+		MinY: strconv.FormatFloat(e.minY, 'f', -1, 64),
+		MaxX: strconv.FormatFloat(e.maxX, 'f', -1, 64),
+		MaxY: strconv.FormatFloat(e.maxY, 'f', -1, 64),
+	}
+}
+
+func (g Geometry) extent() extent {
+	e := extent{minX: math.Inf(1), minY: math.Inf(1), maxX: math.Inf(-1), maxY: math.Inf(-1)}
+	for _, polygon := range g {
+		if len(polygon) == 0 {
+			continue
+		}
+		for _, position := range polygon[0] {
+			e.minX, e.maxX = min(e.minX, position[0]), max(e.maxX, position[0])
+			e.minY, e.maxY = min(e.minY, position[1]), max(e.maxY, position[1])
+		}
+	}
+	return e
+}
+
+// signedArea uses the shoelace formula, the area is positive for counterclockwise rings
+func (r Ring) signedArea() float64 {
+	var area float64
+	for i := 0; i < len(r)-1; i++ {
+		area += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	return area / 2
+}
+
+func parseGeoJSON(s string) (Geometry, error) {
+	var geoJSON struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal([]byte(s), &geoJSON); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var polygons [][][][]float64
+	switch geoJSON.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geoJSON.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON Polygon coordinates: %w", err)
+		}
+		polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geoJSON.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("GeoJSON type %q is not supported, use Polygon or MultiPolygon", geoJSON.Type)
+	}
+
+	geometry := make(Geometry, 0, len(polygons))
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return nil, errors.New("polygon without rings")
+		}
+		rings := make([]Ring, 0, len(polygon))
+		for _, coordinates := range polygon {
+			ring := make(Ring, 0, len(coordinates))
+			for _, coordinate := range coordinates {
+				if len(coordinate) < 2 {
+					return nil, fmt.Errorf("position %v should have at least 2 coordinates", coordinate)
+				}
+				ring = append(ring, Position{coordinate[0], coordinate[1]})
+			}
+			rings = append(rings, ring)
+		}
+		geometry = append(geometry, rings)
+	}
+	if len(geometry) == 0 {
+		return nil, errors.New("geometry is empty")
+	}
+	return geometry, nil
+}
+
+// wktParser parses the coordinates of a WKT Polygon or MultiPolygon
+type wktParser struct {
+	tokens []string
+	pos    int
+}
+
+func parseWKT(s string) (Geometry, error) {
+	keyword, rest, _ := strings.Cut(s, "(")
+	keyword = strings.ToUpper(strings.TrimSpace(keyword))
+	p := &wktParser{tokens: tokenizeWKT("(" + rest)}
+
+	var geometry Geometry
+	switch keyword {
+	case "POLYGON":
+		polygon, err := p.polygon()
+		if err != nil {
+			return nil, err
+		}
+		geometry = Geometry{polygon}
+	case "MULTIPOLYGON":
+		err := p.list(func() error {
+			polygon, err := p.polygon()
+			geometry = append(geometry, polygon)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("WKT type %q is not supported, use POLYGON or MULTIPOLYGON", keyword)
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid WKT: unexpected %q", p.tokens[p.pos])
+	}
+	return geometry, nil
+}
+
+func tokenizeWKT(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// list parses "(" item ("," item)* ")"
+func (p *wktParser) list(item func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if p.pos < len(p.tokens) && p.tokens[p.pos] == "," {
+			p.pos++
+			continue
+		}
+		return p.expect(")")
+	}
+}
+
+func (p *wktParser) polygon() ([]Ring, error) {
+	var rings []Ring
+	err := p.list(func() error {
+		var ring Ring
+		err := p.list(func() error {
+			position, err := p.position()
+			ring = append(ring, position)
+			return err
+		})
+		rings = append(rings, ring)
+		return err
+	})
+	return rings, err
+}
+
+func (p *wktParser) position() (Position, error) {
+	var position Position
+	for i := range position {
+		if p.pos >= len(p.tokens) {
+			return position, errors.New("invalid WKT: unexpected end")
+		}
+		coordinate, err := strconv.ParseFloat(p.tokens[p.pos], 64)
+		if err != nil {
+			return position, fmt.Errorf("invalid WKT: %q is not a number", p.tokens[p.pos])
+		}
+		position[i] = coordinate
+		p.pos++
+	}
+	return position, nil
+}
+
+func (p *wktParser) expect(token string) error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("invalid WKT: expected %q but got the end", token)
+	}
+	if p.tokens[p.pos] != token {
+		return fmt.Errorf("invalid WKT: expected %q but got %q", token, p.tokens[p.pos])
+	}
+	p.pos++
+	return nil
+}
@@ -25,17 +25,20 @@ SOFTWARE.
 package v3
 
 import (
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	smoothoperatormodel "github.com/pdok/smooth-operator/model"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var baseURL string
-var blobEndpoint string
+var epsgURIRegex = regexp.MustCompile(`(?i)/EPSG/[^/]+/([0-9]+)/?$`)
 
 // AtomSpec defines the desired state of Atom.
-// +kubebuilder:validation:XValidation:rule="!has(self.ingressRouteUrls) || self.ingressRouteUrls.exists_one(x, x.url == self.service.baseUrl)",messageExpression="'ingressRouteUrls should include service.baseUrl '+self.service.baseUrl"
+// +kubebuilder:validation:XValidation:rule="!has(self.ingressRouteUrls) || !has(self.service.baseUrl) || self.ingressRouteUrls.exists_one(x, x.url == self.service.baseUrl)",messageExpression="'ingressRouteUrls should include service.baseUrl '+self.service.baseUrl"
 type AtomSpec struct {
 	// Optional lifecycle settings
 	Lifecycle *smoothoperatormodel.Lifecycle `json:"lifecycle,omitempty"`
@@ -46,12 +49,45 @@ type AtomSpec struct {
 
 	// Service specification
 	Service Service `json:"service"`
+
+	// Optional periodic refresh of the feeds, so changes to the type and length of downloads are picked up
+	// without a change to the Atom. Overrides the --refresh-interval of the operator.
+	Refresh *Refresh `json:"refresh,omitempty"`
+
+	// Optional images that override the images of the operator for this Atom, for example to canary a new release.
+	// The images have to be in one of the --allowed-image-registries of the operator.
+	Images *Images `json:"images,omitempty"`
+}
+
+// Images overrides the images used for an Atom
+type Images struct {
+	// Optional atom-generator image, only used by the atom-generator init container of the --legacy-atom-generator
+	// +kubebuilder:validation:MinLength:=1
+	AtomGenerator *string `json:"atomGenerator,omitempty"`
+
+	// Optional lighttpd image that serves the feeds, not used by the shared server or when the feeds are published
+	// +kubebuilder:validation:MinLength:=1
+	Lighttpd *string `json:"lighttpd,omitempty"`
+}
+
+// MinRefreshInterval is the shortest interval between refreshes, every refresh requests the type and length of all downloads
+const MinRefreshInterval = time.Minute
+
+// Refresh configures how often the feeds are rendered again
+// +kubebuilder:validation:XValidation:rule="duration(self.interval) >= duration('1m')",message="interval should be at least 1m"
+type Refresh struct {
+	// Interval between refreshes of at least 1m, for example 1h or 30m
+	Interval metav1.Duration `json:"interval"`
 }
 
 // Service defines the service configuration for the Atom feed
+// +kubebuilder:validation:XValidation:rule="has(self.rights) || has(self.license)",message="rights or license is required"
 type Service struct {
 	// BaseURL of the Atom service. Will be suffixed with index.xml for the index.
-	BaseURL smoothoperatormodel.URL `json:"baseUrl"`
+	// When omitted it is derived from the --atom-baseurl of the operator and the
+	// pdok.nl/owner-id, pdok.nl/dataset-id, pdok.nl/tag and pdok.nl/service-version labels.
+	// +optional
+	BaseURL smoothoperatormodel.URL `json:"baseUrl,omitzero"`
 
 	// Language of the service
 	// +kubebuilder:default:="nl"
@@ -61,6 +97,10 @@ type Service struct {
 	// Optional link to a stylesheet used in pages generated by the service.
 	Stylesheet *smoothoperatormodel.URL `json:"stylesheet,omitempty"`
 
+	// Optional JSON rendering of the feeds that follows the OGC API Records record model, served as index.json
+	// and technicalName.json next to the feeds. Not available with the --legacy-atom-generator of the operator.
+	Records bool `json:"records,omitempty"`
+
 	// Title of the service
 	// +kubebuilder:validation:MinLength:=1
 	Title string `json:"title"`
@@ -79,9 +119,28 @@ type Service struct {
 	// +kubebuilder:validation:MinItems:=1
 	Links []Link `json:"links,omitempty"`
 
-	// License used
+	// Rights of the service as free text. When omitted it is generated from the license.
 	// +kubebuilder:validation:MinLength:=1
-	Rights string `json:"rights"`
+	// +optional
+	Rights string `json:"rights,omitempty"`
+
+	// Optional machine-readable license of the service, added as rel=license link to the feeds
+	License *License `json:"license,omitempty"`
+
+	// Optional time the service was last updated. If omitted the newest updated of the dataset feeds is used
+	// +kubebuilder:validation:Format:=date-time
+	Updated *metav1.Time `json:"updated,omitempty"`
+
+	// Optional keywords of the service, added as category to the service feed.
+	// Keywords and INSPIRE themes are not available with the --legacy-atom-generator of the operator.
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:items:MinLength:=1
+	// +listType=set
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Optional INSPIRE themes of the service, added as category with the INSPIRE theme register as scheme
+	// +kubebuilder:validation:MinItems:=1
+	InspireThemes []InspireTheme `json:"inspireThemes,omitempty"`
 
 	// DatasetFeeds in this service
 	// +kubebuilder:validation:MinItems:=1
@@ -90,7 +149,9 @@ type Service struct {
 
 // Link represents a link in the service or dataset feed
 type Link struct {
-	// Actual href of the link
+	// Actual href of the link. In a dataset feed the href is a mustache template with the variables
+	// lang, baseUrl, ownerInfo, technicalName, spatialDatasetIdentifierCode, spatialDatasetIdentifierNamespace
+	// and, when datasetMetadataLinks is set, identifier. Other variables are rejected.
 	Href smoothoperatormodel.URL `json:"href"`
 
 	// Relation (type) of the link, for example: describedby, self or alternate
@@ -98,7 +159,7 @@ type Link struct {
 	Rel string `json:"rel"`
 
 	// ContentType of the link, for example: application/pdf or text/plain
-	// +kubebuilder:validation:Pattern:=`^[a-z]+\/[a-z]+(?:\+[a-z]+)?$`
+	// +kubebuilder:validation:Pattern:=`^[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]{0,126}\/[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]{0,126}(\s*;.*)?$`
 	Type string `json:"type"`
 
 	// Optional language of the link. If omitted the language of the service is used
@@ -134,6 +195,25 @@ type DatasetFeed struct {
 	// Author of the dataset, note that this is not the same as the author of the service.
 	Author smoothoperatormodel.Author `json:"author"`
 
+	// Optional license of the dataset. If omitted the rights and license of the service are used
+	License *License `json:"license,omitempty"`
+
+	// Optional time the dataset was last updated, used by the dataset feed and its entry in the service feed.
+	// If omitted the newest updated of the entries in the feed is used, so it is required when all entries are archived.
+	// The archive feed always uses its newest entry
+	// +kubebuilder:validation:Format:=date-time
+	Updated *metav1.Time `json:"updated,omitempty"`
+
+	// Optional keywords of the dataset, added as category to the dataset feed
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:items:MinLength:=1
+	// +listType=set
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Optional INSPIRE themes of the dataset, added as category with the INSPIRE theme register as scheme
+	// +kubebuilder:validation:MinItems:=1
+	InspireThemes []InspireTheme `json:"inspireThemes,omitempty"`
+
 	// SpatialDatasetIdentifierCode
 	// +kubebuilder:validation:Pattern:=`^[0-9a-zA-Z]{8}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{12}$`
 	SpatialDatasetIdentifierCode *string `json:"spatialDatasetIdentifierCode,omitempty"`
@@ -143,9 +223,73 @@ type DatasetFeed struct {
 	// +kubebuilder:validation:Format:=uri
 	SpatialDatasetIdentifierNamespace *string `json:"spatialDatasetIdentifierNamespace,omitempty"`
 
+	// Optional links to services that give direct access to the dataset, for example a WFS
+	// +kubebuilder:validation:MinItems:=1
+	ServiceLinks []ServiceLink `json:"serviceLinks,omitempty"`
+
 	// List of entries for this dataset feed, typically used for downloads
 	// +kubebuilder:validation:MinItems:=1
 	Entries []Entry `json:"entries"`
+
+	// Optional archive for older versions of the dataset. Archived entries are published in
+	// technicalName-archive.xml, which is linked from the feed with rel prev-archive (RFC 5005).
+	Archive *Archive `json:"archive,omitempty"`
+
+	// Optional maximum number of entries per page. When set the feed is split into technicalName.xml,
+	// technicalName-2.xml, etc. that link to each other as paged feed (RFC 5005).
+	// Paging does not lift the size limit of the feeds: unless they are published to blob storage,
+	// all feeds of the Atom are served from a single ConfigMap of at most 1 MiB.
+	// +kubebuilder:validation:Minimum:=1
+	PageSize *int32 `json:"pageSize,omitempty"`
+}
+
+// License is a machine-readable license, for example CC0 1.0 or CC BY 4.0
+type License struct {
+	// URI of the license, for example https://creativecommons.org/licenses/by/4.0/
+	URI smoothoperatormodel.URL `json:"uri"`
+
+	// Label of the license, for example CC BY 4.0
+	// +kubebuilder:validation:MinLength:=1
+	Label string `json:"label"`
+
+	// Optional attribution the license requires, for example the name of the source
+	// +kubebuilder:validation:MinLength:=1
+	Attribution *string `json:"attribution,omitempty"`
+}
+
+// InspireTheme is a reference to a theme of the INSPIRE theme register
+type InspireTheme struct {
+	// URI of the theme, for example http://inspire.ec.europa.eu/theme/au
+	URI smoothoperatormodel.URL `json:"uri"`
+
+	// Optional label of the theme, for example Bestuurlijke eenheden. If omitted the English label of the register is used
+	// +kubebuilder:validation:MinLength:=1
+	Label *string `json:"label,omitempty"`
+}
+
+// Archive configures the archive feed of a dataset feed
+type Archive struct {
+	// Optional title of the archive feed. If omitted the title of the dataset feed is used
+	// +kubebuilder:validation:MinLength:=1
+	Title *string `json:"title,omitempty"`
+}
+
+// ServiceLink is a link to a layer or collection of a service that gives direct access to the data
+type ServiceLink struct {
+	// Protocol of the service
+	// +kubebuilder:validation:Enum:=wfs;wcs;ogcapi-features
+	Protocol string `json:"protocol"`
+
+	// Endpoint of the service, without request parameters. For OGC API this is the landing page.
+	Endpoint smoothoperatormodel.URL `json:"endpoint"`
+
+	// Name of the feature type, coverage or collection
+	// +kubebuilder:validation:MinLength:=1
+	Layer string `json:"layer"`
+
+	// Optional title of the link
+	// +kubebuilder:validation:MinLength:=1
+	Title *string `json:"title,omitempty"`
 }
 
 // MetadataLink represents a link in the service or dataset feed
@@ -154,9 +298,12 @@ type MetadataLink struct {
 	// +kubebuilder:validation:Pattern:=`^[0-9a-zA-Z]{8}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{4}\-[0-9a-zA-Z]{12}$`
 	MetadataIdentifier string `json:"metadataIdentifier"`
 
-	// Metadata templates to use
+	// Metadata templates to use: csw, opensearch, html or the name of a template in the
+	// pdok.nl/atom-metadata-link-templates annotation of the OwnerInfo. The href templates have the variables
+	// identifier, lang, baseUrl and ownerInfo, for a dataset feed also technicalName, spatialDatasetIdentifierCode
+	// and spatialDatasetIdentifierNamespace. Other variables are rejected.
 	// +kubebuilder:validation:MinItems:=1
-	// +kubebuilder:validation:items:Enum:=csw;opensearch;html
+	// +kubebuilder:validation:items:Pattern:=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	Templates []string `json:"templates"`
 }
 
@@ -179,11 +326,22 @@ type Entry struct {
 	// +kubebuilder:validation:MinItems:=1
 	DownloadLinks []DownloadLink `json:"downloadlinks"`
 
+	// Optional links to services that give direct access to the data of this entry
+	// +kubebuilder:validation:MinItems:=1
+	ServiceLinks []ServiceLink `json:"serviceLinks,omitempty"`
+
 	// Last updated timestamp
 	// +kubebuilder:validation:Format:=date-time
 	Updated metav1.Time `json:"updated"`
 
-	// Bounding box of the entry
+	// Optional version of the data in this entry, for use in historical datasets
+	// +kubebuilder:validation:MinLength:=1
+	Version *string `json:"version,omitempty"`
+
+	// Publish the entry in the archive feed instead of the dataset feed. Requires an archive on the dataset feed.
+	Archived bool `json:"archived,omitempty"`
+
+	// Area of the entry
 	Polygon Polygon `json:"polygon"`
 
 	// Spatial Reference System
@@ -206,11 +364,29 @@ type DownloadLink struct {
 
 	// Optional boundingbox of the data. If omitted the bounding box of the parent entry is used
 	BBox *smoothoperatormodel.BBox `json:"bbox,omitempty"`
+
+	// Optional media type of the data, for example: application/geopackage+sqlite3.
+	// If type and length are both set the data is not requested to determine them.
+	// +kubebuilder:validation:Pattern:=`^[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]{0,126}\/[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]{0,126}(\s*;.*)?$`
+	Type *string `json:"type,omitempty"`
+
+	// Optional length of the data in bytes
+	// +kubebuilder:validation:Minimum:=0
+	Length *int64 `json:"length,omitempty"`
 }
 
-// Polygon describes the bounding box of an entry or download
+// Polygon describes the area of an entry, as a bounding box and optionally the exact geometry
+// +kubebuilder:validation:XValidation:rule="has(self.bbox) || has(self.geometry)",message="bbox or geometry is required"
 type Polygon struct {
-	BBox smoothoperatormodel.BBox `json:"bbox"`
+	// Bounding box of the area. Derived from the geometry when omitted.
+	// +optional
+	BBox smoothoperatormodel.BBox `json:"bbox,omitzero"`
+
+	// Optional Polygon or MultiPolygon of the area as GeoJSON geometry or WKT, in the SRS of the entry.
+	// Rings must be closed, exterior rings counterclockwise and interior rings clockwise.
+	// Multipolygons and polygons with holes are published as GML in a georss:where.
+	// +kubebuilder:validation:MinLength:=1
+	Geometry *string `json:"geometry,omitempty"`
 }
 
 // SRS describes the Spatial Reference System for an entry
@@ -223,6 +399,51 @@ type SRS struct {
 	Name string `json:"name"`
 }
 
+// RefreshResult is the result of a refresh of the feeds
+// +kubebuilder:validation:Enum:=Changed;Unchanged;Failed
+type RefreshResult string
+
+const (
+	// RefreshResultChanged means the feeds were rendered differently and are rolled out
+	RefreshResultChanged RefreshResult = "Changed"
+	// RefreshResultUnchanged means the feeds were rendered the same as before
+	RefreshResultUnchanged RefreshResult = "Unchanged"
+	// RefreshResultFailed means the feeds could not be refreshed
+	RefreshResultFailed RefreshResult = "Failed"
+)
+
+// AtomStatus defines the observed state of Atom.
+type AtomStatus struct {
+	smoothoperatormodel.OperatorStatus `json:",inline"`
+
+	// Last periodic refresh of the feeds, only set when refreshing is enabled
+	Refresh *RefreshStatus `json:"refresh,omitempty"`
+
+	// Images that the pods serving the feeds of this Atom run, during a rollout these include the previous images
+	Images []ContainerImage `json:"images,omitempty"`
+}
+
+// ContainerImage is the image of a container
+type ContainerImage struct {
+	// Name of the container
+	Container string `json:"container"`
+
+	// Image of the container
+	Image string `json:"image"`
+}
+
+// RefreshStatus records the last refresh of the feeds
+type RefreshStatus struct {
+	// Time of the last refresh
+	LastRefreshTime metav1.Time `json:"lastRefreshTime"`
+
+	// Result of the last refresh
+	Result RefreshResult `json:"result"`
+
+	// Optional message, the error when the refresh failed
+	Message string `json:"message,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:conversion:hub
 // +kubebuilder:subresource:status
@@ -238,12 +459,12 @@ type Atom struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   AtomSpec                           `json:"spec"`
-	Status smoothoperatormodel.OperatorStatus `json:"status,omitempty"`
+	Spec   AtomSpec   `json:"spec"`
+	Status AtomStatus `json:"status,omitempty"`
 }
 
 func (a *Atom) OperatorStatus() *smoothoperatormodel.OperatorStatus {
-	return &a.Status
+	return &a.Status.OperatorStatus
 }
 
 // +kubebuilder:object:root=true
@@ -259,20 +480,157 @@ func init() {
 	SchemeBuilder.Register(&Atom{}, &AtomList{})
 }
 
-func SetBaseURL(atomBaseURL string) {
-	baseURL = strings.TrimSuffix(atomBaseURL, "/")
+// CreateBaseURL creates the base URL of a service following the convention host/owner/dataset/[theme/]atom[/serviceVersion]
+func CreateBaseURL(host, owner, dataset string, theme, serviceVersion *string) (*smoothoperatormodel.URL, error) {
+	serviceURL, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	serviceURL = serviceURL.JoinPath(owner, dataset)
+	if theme != nil {
+		serviceURL = serviceURL.JoinPath(*theme)
+	}
+	serviceURL = serviceURL.JoinPath("atom")
+
+	if serviceVersion != nil {
+		serviceURL = serviceURL.JoinPath(*serviceVersion)
+	}
+
+	return &smoothoperatormodel.URL{URL: serviceURL}, nil
+}
+
+// GetIngressRouteURLs returns the URLs the service is available on, which is only the baseUrl when spec.ingressRouteUrls is empty
+func (a *Atom) GetIngressRouteURLs() smoothoperatormodel.IngressRouteURLs {
+	if len(a.Spec.IngressRouteURLs) > 0 {
+		return a.Spec.IngressRouteURLs
+	}
+	return smoothoperatormodel.IngressRouteURLs{{URL: a.Spec.Service.BaseURL}}
+}
+
+// GetLicense returns the license of the dataset feed, which is the license of the service when the
+// dataset feed has none. A nil dataset feed returns the license of the service.
+func (service *Service) GetLicense(datasetFeed *DatasetFeed) *License {
+	if datasetFeed != nil && datasetFeed.License != nil {
+		return datasetFeed.License
+	}
+	return service.License
+}
+
+// GetRights returns the rights text of the dataset feed, or of the service when the dataset feed is nil.
+// The rights of the service are used unless the dataset feed has its own license, otherwise the text
+// is generated from the license.
+func (service *Service) GetRights(datasetFeed *DatasetFeed) string {
+	if service.Rights != "" && (datasetFeed == nil || datasetFeed.License == nil) {
+		return service.Rights
+	}
+	if license := service.GetLicense(datasetFeed); license != nil {
+		return license.GetRights()
+	}
+	return service.Rights
+}
+
+// GetRights returns the rights text of the license: its label followed by the attribution, if any
+func (license *License) GetRights() string {
+	if license.Attribution == nil {
+		return license.Label
+	}
+	return strings.TrimSuffix(license.Label, ".") + ". " + *license.Attribution
+}
+
+// GetCurrentEntries returns the entries that are published in the dataset feed itself
+func (datasetFeed *DatasetFeed) GetCurrentEntries() []Entry {
+	if datasetFeed.Archive == nil {
+		return datasetFeed.Entries
+	}
+	var entries []Entry
+	for _, entry := range datasetFeed.Entries {
+		if !entry.Archived {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// GetArchivedEntries returns the entries that are published in the archive feed
+func (datasetFeed *DatasetFeed) GetArchivedEntries() []Entry {
+	if datasetFeed.Archive == nil {
+		return nil
+	}
+	var entries []Entry
+	for _, entry := range datasetFeed.Entries {
+		if entry.Archived {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// GetPages returns the current entries per page of the dataset feed, a feed without page size has a single page
+func (datasetFeed *DatasetFeed) GetPages() [][]Entry {
+	currentEntries := datasetFeed.GetCurrentEntries()
+	if datasetFeed.PageSize == nil || *datasetFeed.PageSize < 1 || len(currentEntries) == 0 {
+		return [][]Entry{currentEntries}
+	}
+
+	var pages [][]Entry
+	for entries := currentEntries; len(entries) > 0; {
+		pageSize := min(int(*datasetFeed.PageSize), len(entries))
+		pages = append(pages, entries[:pageSize])
+		entries = entries[pageSize:]
+	}
+	return pages
+}
+
+// GetPageFileName returns the file name of the page of the dataset feed, page 1 is technicalName.xml
+func (datasetFeed *DatasetFeed) GetPageFileName(page int) string {
+	if page <= 1 {
+		return datasetFeed.TechnicalName + ".xml"
+	}
+	return datasetFeed.TechnicalName + "-" + strconv.Itoa(page) + ".xml"
 }
 
-func GetBaseURL() string {
-	return baseURL
+// GetArchiveFileName returns the file name of the archive feed of the dataset feed
+func (datasetFeed *DatasetFeed) GetArchiveFileName() string {
+	return datasetFeed.TechnicalName + "-archive.xml"
+}
+
+// GetFeedFileNames returns the file names of all pages and the archive of the dataset feed
+func (datasetFeed *DatasetFeed) GetFeedFileNames() []string {
+	var fileNames []string
+	for page := range datasetFeed.GetPages() {
+		fileNames = append(fileNames, datasetFeed.GetPageFileName(page+1))
+	}
+	if datasetFeed.Archive != nil {
+		fileNames = append(fileNames, datasetFeed.GetArchiveFileName())
+	}
+	return fileNames
+}
+
+const (
+	// DCATJSONLDFileName is the file name of the DCAT-AP document of an Atom as JSON-LD
+	DCATJSONLDFileName = "dcat.jsonld"
+	// DCATRDFFileName is the file name of the DCAT-AP document of an Atom as RDF/XML
+	DCATRDFFileName = "dcat.rdf"
+)
+
+// GetHTMLFileName returns the file name of the HTML page of a feed
+func GetHTMLFileName(feedFileName string) string {
+	return strings.TrimSuffix(feedFileName, ".xml") + ".html"
 }
 
-func SetBlobEndpoint(atomBlobEndpoint string) {
-	blobEndpoint = atomBlobEndpoint
+// GetJSONFileName returns the file name of the OGC API Records JSON of a feed
+func GetJSONFileName(feedFileName string) string {
+	return strings.TrimSuffix(feedFileName, ".xml") + ".json"
 }
 
-func GetBlobEndpoint() string {
-	return blobEndpoint
+// GetRenderedFileNames returns the file names the operator renders for a feed of the service: the feed itself,
+// its HTML page and with records its JSON
+func (service *Service) GetRenderedFileNames(feedFileName string) []string {
+	fileNames := []string{feedFileName, GetHTMLFileName(feedFileName)}
+	if service.Records {
+		fileNames = append(fileNames, GetJSONFileName(feedFileName))
+	}
+	return fileNames
 }
 
 func (a *Atom) GetDownloadLinks() (downloadLinks []DownloadLink) {
@@ -284,6 +642,37 @@ func (a *Atom) GetDownloadLinks() (downloadLinks []DownloadLink) {
 	return
 }
 
+// GetDownloadLinkRel returns the rel of the download link. When not set, links are
+// "section" when the entry has multiple links without rel and "alternate" otherwise.
+func (entry *Entry) GetDownloadLinkRel(downloadLink DownloadLink) string {
+	if downloadLink.Rel != nil && *downloadLink.Rel != "" {
+		return *downloadLink.Rel
+	}
+
+	emptyRelCount := 0
+	for _, link := range entry.DownloadLinks {
+		if link.Rel == nil || *link.Rel == "" {
+			emptyRelCount++
+		}
+	}
+	if emptyRelCount > 1 {
+		return "section"
+	}
+	return "alternate"
+}
+
+// GetBBox returns the bounding box of the polygon, derived from the geometry when the bbox is not set
+func (p Polygon) GetBBox() smoothoperatormodel.BBox {
+	if p.BBox != (smoothoperatormodel.BBox{}) || p.Geometry == nil {
+		return p.BBox
+	}
+	geometry, err := ParseGeometry(*p.Geometry)
+	if err != nil {
+		return p.BBox
+	}
+	return geometry.BBox()
+}
+
 func (dl *DownloadLink) GetBlobPrefix() string {
 	index := strings.LastIndex(dl.Data, "/")
 	return dl.Data[:index]
@@ -293,3 +682,19 @@ func (dl *DownloadLink) GetBlobName() string {
 	index := strings.LastIndex(dl.Data, "/") + 1
 	return dl.Data[index:]
 }
+
+// GetEPSGCode returns the EPSG code from an OGC URI like http://www.opengis.net/def/crs/EPSG/0/28992
+func (srs *SRS) GetEPSGCode() (int, bool) {
+	if srs.URI.URL == nil {
+		return 0, false
+	}
+	match := epsgURIRegex.FindStringSubmatch(srs.URI.Path)
+	if match == nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
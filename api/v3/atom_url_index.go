@@ -0,0 +1,167 @@
+package v3
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// URLIndexKey indexes Atoms on the (host and path of the) URLs they are served on
+	URLIndexKey = "spec.service.urls"
+	// URLPrefixIndexKey indexes Atoms on all parent paths of the URLs they are served on
+	URLPrefixIndexKey = "spec.service.urlPrefixes"
+)
+
+// IndexURLs is the index function for URLIndexKey
+func IndexURLs(obj client.Object) []string {
+	atom, ok := obj.(*Atom)
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for _, ingressRouteURL := range atom.GetIngressRouteURLs() {
+		if key := getURLIndexKey(ingressRouteURL.URL); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// IndexURLPrefixes is the index function for URLPrefixIndexKey
+func IndexURLPrefixes(obj client.Object) []string {
+	var keys []string
+	for _, urlKey := range IndexURLs(obj) {
+		for _, prefix := range getURLIndexKeyPrefixes(urlKey) {
+			if !slices.Contains(keys, prefix) {
+				keys = append(keys, prefix)
+			}
+		}
+	}
+	return keys
+}
+
+type urlLookup struct {
+	indexKey string
+	value    string
+}
+
+var urlIndexFuncs = map[string]client.IndexerFunc{
+	URLIndexKey:       IndexURLs,
+	URLPrefixIndexKey: IndexURLPrefixes,
+}
+
+// getURLIndexKey returns the host and cleaned path of the URL, e.g. service.pdok.nl/owner/dataset/atom
+func getURLIndexKey(u smoothoperatormodel.URL) string {
+	if u.URL == nil {
+		return ""
+	}
+	return strings.ToLower(u.Host) + strings.TrimSuffix(path.Clean("/"+u.Path), "/")
+}
+
+// getURLIndexKeyPrefixes returns the parent paths of an index key, e.g. service.pdok.nl/owner and service.pdok.nl/owner/dataset
+func getURLIndexKeyPrefixes(key string) []string {
+	var prefixes []string
+	for i := strings.Index(key, "/"); i >= 0; {
+		next := strings.Index(key[i+1:], "/")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+		prefixes = append(prefixes, key[:i])
+	}
+	return prefixes
+}
+
+// ValidateURLCollisions checks that no other Atom is served on the same URL, or on a URL that is a parent or child path of one of the URLs of this Atom
+func ValidateURLCollisions(c client.Client, atom *Atom, allErrs *field.ErrorList) {
+	ctx := context.Background()
+
+	fieldPath := field.NewPath("spec").Child("ingressRouteUrls")
+	if len(atom.Spec.IngressRouteURLs) == 0 {
+		fieldPath = field.NewPath("spec").Child("service").Child("baseUrl")
+	}
+
+	for i, ingressRouteURL := range atom.GetIngressRouteURLs() {
+		urlPath := fieldPath
+		if len(atom.Spec.IngressRouteURLs) > 0 {
+			urlPath = fieldPath.Index(i).Child("url")
+		}
+
+		key := getURLIndexKey(ingressRouteURL.URL)
+		if key == "" {
+			continue
+		}
+
+		// Same URL, URLs below this URL and URLs above this URL
+		lookups := []urlLookup{{URLIndexKey, key}, {URLPrefixIndexKey, key}}
+		for _, prefix := range getURLIndexKeyPrefixes(key) {
+			lookups = append(lookups, urlLookup{URLIndexKey, prefix})
+		}
+
+		var conflicts []string
+		for _, lookup := range lookups {
+			others, err := listAtomsByIndex(ctx, c, lookup.indexKey, lookup.value)
+			if err != nil {
+				*allErrs = append(*allErrs, field.InternalError(urlPath, err))
+				return
+			}
+
+			for _, other := range others {
+				if other.Namespace == atom.Namespace && other.Name == atom.Name {
+					continue
+				}
+
+				var conflict string
+				switch {
+				case lookup.indexKey == URLIndexKey && lookup.value == key:
+					conflict = fmt.Sprintf("is already used by Atom %s/%s", other.Namespace, other.Name)
+				case lookup.indexKey == URLIndexKey:
+					conflict = fmt.Sprintf("is a sub path of %s used by Atom %s/%s", lookup.value, other.Namespace, other.Name)
+				default:
+					conflict = fmt.Sprintf("is a parent path of a URL used by Atom %s/%s", other.Namespace, other.Name)
+				}
+				if !slices.Contains(conflicts, conflict) {
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+
+		for _, conflict := range conflicts {
+			*allErrs = append(*allErrs, field.Invalid(urlPath, ingressRouteURL.URL.String(), conflict))
+		}
+	}
+}
+
+// listAtomsByIndex lists all Atoms that have the value in the index. The index is registered on the cache of the manager,
+// a client that reads from the API server instead (for example in tests) gets the field selector rejected as a bad request.
+// Only in that case all Atoms are listed and filtered with the index function.
+func listAtomsByIndex(ctx context.Context, c client.Client, indexKey, value string) ([]Atom, error) {
+	atoms := &AtomList{}
+	err := c.List(ctx, atoms, client.MatchingFields{indexKey: value})
+	if err == nil {
+		return atoms.Items, nil
+	}
+	if !apierrors.IsBadRequest(err) {
+		return nil, err
+	}
+
+	if err := c.List(ctx, atoms); err != nil {
+		return nil, err
+	}
+	var result []Atom
+	for _, atom := range atoms.Items {
+		if slices.Contains(urlIndexFuncs[indexKey](&atom), value) {
+			result = append(result, atom)
+		}
+	}
+	return result, nil
+}
@@ -2,11 +2,16 @@ package v3
 
 import (
 	"fmt"
+	"mime"
 	"slices"
+	"strconv"
+	"time"
 
 	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
 	smoothoperatorvalidation "github.com/pdok/smooth-operator/pkg/validation"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
@@ -16,11 +21,32 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (atom *Atom) ValidateCreate(c client.Client) ([]string, error) {
+// extent holds the parsed coordinates of a bounding box
+type extent struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (e extent) contains(other extent) bool {
+	return other.minX >= e.minX && other.minY >= e.minY && other.maxX <= e.maxX && other.maxY <= e.maxY
+}
+
+// plausibleExtents holds the area in which coordinates are expected per EPSG code, in the axis order of the bbox (x = east/longitude).
+// The areas roughly follow the area of use of the SRS, they are meant to catch swapped or mixed up coordinates, not to be exact.
+var plausibleExtents = map[int]extent{
+	28992: {minX: -7000, minY: 289000, maxX: 300000, maxY: 650000},      // Amersfoort / RD New
+	4258:  {minX: -16.1, minY: 32.88, maxX: 40.18, maxY: 84.73},         // ETRS89
+	4326:  {minX: -180, minY: -90, maxX: 180, maxY: 90},                 // WGS 84
+	3035:  {minX: 1500000, minY: 1000000, maxX: 7500000, maxY: 6000000}, // ETRS89-extended / LAEA Europe
+	25831: {minX: 100000, minY: 3500000, maxX: 900000, maxY: 8500000},   // ETRS89 / UTM zone 31N
+	25832: {minX: 100000, minY: 3500000, maxX: 900000, maxY: 8500000},   // ETRS89 / UTM zone 32N
+}
+
+// ValidateCreate validates the creation of the Atom, its images should come from one of the allowedImageRegistries
+func (atom *Atom) ValidateCreate(c client.Client, allowedImageRegistries []string) ([]string, error) {
 	var warnings []string
 	var allErrs field.ErrorList
 
-	validateCreate(&c, atom, &warnings, &allErrs)
+	validateCreate(&c, allowedImageRegistries, atom, &warnings, &allErrs)
 
 	if len(allErrs) == 0 {
 		return warnings, nil
@@ -31,11 +57,12 @@ func (atom *Atom) ValidateCreate(c client.Client) ([]string, error) {
 		atom.Name, allErrs)
 }
 
-func (atom *Atom) ValidateUpdate(c client.Client, atomOld *Atom) ([]string, error) {
+// ValidateUpdate validates the update of the Atom, its images should come from one of the allowedImageRegistries
+func (atom *Atom) ValidateUpdate(c client.Client, allowedImageRegistries []string, atomOld *Atom) ([]string, error) {
 	var warnings []string
 	var allErrs field.ErrorList
 
-	validateUpdate(&c, atom, atomOld, &warnings, &allErrs)
+	validateUpdate(&c, allowedImageRegistries, atom, atomOld, &warnings, &allErrs)
 
 	if len(allErrs) == 0 {
 		return warnings, nil
@@ -48,12 +75,12 @@ func (atom *Atom) ValidateUpdate(c client.Client, atomOld *Atom) ([]string, erro
 
 // ValidateCreateAtom validates Atom creation without k8s client
 func ValidateCreateAtom(atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
-	validateCreate(nil, atom, warnings, allErrs)
+	validateCreate(nil, nil, atom, warnings, allErrs)
 }
 
 // ValidateUpdateAtom validates Atom update without k8s client
 func ValidateUpdateAtom(atom *Atom, atomOld *Atom, warnings *[]string, allErrs *field.ErrorList) {
-	validateUpdate(nil, atom, atomOld, warnings, allErrs)
+	validateUpdate(nil, nil, atom, atomOld, warnings, allErrs)
 }
 
 func ValidateOwnerInfo(c client.Client, atom *Atom, allErrs *field.ErrorList) {
@@ -89,8 +116,8 @@ func validateMetadataTemplates(atom *Atom, ownerInfo *smoothoperatorv1.OwnerInfo
 		}
 	}
 
+	fieldPath := field.NewPath("spec").Child("service").Child("ownerInfoRef")
 	if len(metadataTemplates) > 0 {
-		fieldPath := field.NewPath("spec").Child("service").Child("ownerInfoRef")
 		if slices.Contains(metadataTemplates, "csw") && (ownerInfo.Spec.MetadataUrls == nil || ownerInfo.Spec.MetadataUrls.CSW == nil) {
 			*allErrs = append(*allErrs, field.Required(fieldPath, "spec.metadataUrls.csw missing in "+ownerInfo.Name))
 		}
@@ -101,26 +128,110 @@ func validateMetadataTemplates(atom *Atom, ownerInfo *smoothoperatorv1.OwnerInfo
 			*allErrs = append(*allErrs, field.Required(fieldPath, "spec.metadataUrls.opensearch missing in "+ownerInfo.Name))
 		}
 	}
+
+	servicePath := field.NewPath("spec").Child("service")
+	if metadataLinks := atom.Spec.Service.ServiceMetadataLinks; metadataLinks != nil {
+		validateMetadataLinkTemplates(*metadataLinks, ownerInfo, GetServiceHrefVariables(atom), servicePath.Child("serviceMetadataLinks"), allErrs,
+			MetadataLinkContextService)
+	}
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		if metadataLinks := datasetFeed.DatasetMetadataLinks; metadataLinks != nil {
+			validateMetadataLinkTemplates(*metadataLinks, ownerInfo, GetDatasetHrefVariables(atom, datasetFeed), servicePath.Child("datasetFeeds").Index(i).Child("datasetMetadataLinks"), allErrs,
+				MetadataLinkContextDatasetEntry, MetadataLinkContextDatasetFeed)
+		}
+	}
+}
+
+// validateMetadataLinkTemplates checks that the templates that are not built in exist in the OwnerInfo and are valid, that they
+// apply to one of the contexts the metadata links are used in, and that the href templates only use the variables of those contexts.
+// Missing built in templates are reported by validateMetadataTemplates.
+func validateMetadataLinkTemplates(metadataLinks MetadataLink, ownerInfo *smoothoperatorv1.OwnerInfo, variables map[string]string, fieldPath *field.Path, allErrs *field.ErrorList, contexts ...MetadataLinkContext) {
+	for i, name := range metadataLinks.Templates {
+		templatePath := fieldPath.Child("templates").Index(i)
+		template, err := GetMetadataLinkTemplate(ownerInfo, name)
+		if err != nil {
+			*allErrs = append(*allErrs, field.Invalid(templatePath, name, err.Error()))
+			continue
+		}
+		if template == nil {
+			if !slices.Contains(builtinMetadataLinkTemplates, name) {
+				*allErrs = append(*allErrs, field.NotFound(templatePath, name))
+			}
+			continue
+		}
+		if !slices.ContainsFunc(contexts, template.AppliesTo) {
+			*allErrs = append(*allErrs, field.Invalid(templatePath, name,
+				fmt.Sprintf("template does not apply to %s", strings.Join(getContextNames(contexts), " or "))))
+			continue
+		}
+		if _, err := RenderHrefTemplate(template.HrefTemplate, variables); err != nil {
+			*allErrs = append(*allErrs, field.Invalid(templatePath, name, err.Error()))
+		}
+	}
+}
+
+// validateLinkHrefs checks that the templated hrefs of the links of the dataset feeds only use the variables of the dataset feed
+func validateLinkHrefs(atom *Atom, allErrs *field.ErrorList) {
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		variables := GetDatasetHrefVariables(atom, datasetFeed)
+		for j, link := range datasetFeed.Links {
+			hrefTemplate := GetHrefTemplate(link.Href)
+			if _, err := RenderHrefTemplate(hrefTemplate, variables); err != nil {
+				fieldPath := field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i).Child("links").Index(j).Child("href")
+				*allErrs = append(*allErrs, field.Invalid(fieldPath, hrefTemplate, err.Error()))
+			}
+		}
+	}
+}
+
+func getContextNames(contexts []MetadataLinkContext) []string {
+	names := make([]string, 0, len(contexts))
+	for _, context := range contexts {
+		names = append(names, string(context))
+	}
+	return names
+}
+
+// validateBaseURLPresent checks that the baseUrl was set or could be derived by the defaulting webhook
+func validateBaseURLPresent(atom *Atom, allErrs *field.ErrorList) bool {
+	if atom.Spec.Service.BaseURL.URL != nil {
+		return true
+	}
+	*allErrs = append(*allErrs, field.Required(
+		field.NewPath("spec").Child("service").Child("baseUrl"),
+		"set it, or set the "+ownerIDLabel+" and "+datasetIDLabel+" labels to derive it from",
+	))
+	return false
 }
 
-func validateCreate(c *client.Client, atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
+func validateCreate(c *client.Client, allowedImageRegistries []string, atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
 	err := smoothoperatorvalidation.ValidateLabelsOnCreate(atom.Labels)
 	if err != nil {
 		*allErrs = append(*allErrs, err)
 	}
 
+	if !validateBaseURLPresent(atom, allErrs) {
+		return
+	}
+
 	ValidateAtom(atom, warnings, allErrs)
 
-	// Only validate owner info if k8s client is available
+	// Only validate owner info, other Atoms and images if k8s client is available, the allowed image registries come with it
 	if c != nil {
 		ValidateOwnerInfo(*c, atom, allErrs)
+		ValidateURLCollisions(*c, atom, allErrs)
+		validateImages(atom, allowedImageRegistries, allErrs)
 	}
 
 }
 
-func validateUpdate(c *client.Client, atom *Atom, atomOld *Atom, warnings *[]string, allErrs *field.ErrorList) {
+func validateUpdate(c *client.Client, allowedImageRegistries []string, atom *Atom, atomOld *Atom, warnings *[]string, allErrs *field.ErrorList) {
 	smoothoperatorvalidation.ValidateLabelsOnUpdate(atomOld.Labels, atom.Labels, allErrs)
 
+	if !validateBaseURLPresent(atom, allErrs) {
+		return
+	}
+
 	if atom.Spec.IngressRouteURLs == nil {
 		smoothoperatorvalidation.CheckURLImmutability(
 			atomOld.Spec.Service.BaseURL,
@@ -138,10 +249,13 @@ func validateUpdate(c *client.Client, atom *Atom, atomOld *Atom, warnings *[]str
 	smoothoperatorvalidation.ValidateIngressRouteURLsNotRemoved(atomOld.Spec.IngressRouteURLs, atom.Spec.IngressRouteURLs, allErrs, nil)
 
 	ValidateAtom(atom, warnings, allErrs)
+	validateArchivedEntries(atom, atomOld, warnings)
 
-	// Only validate owner info if k8s client is available
+	// Only validate owner info, other Atoms and images if k8s client is available, the allowed image registries come with it
 	if c != nil {
 		ValidateOwnerInfo(*c, atom, allErrs)
+		ValidateURLCollisions(*c, atom, allErrs)
+		validateImages(atom, allowedImageRegistries, allErrs)
 	}
 }
 
@@ -152,7 +266,15 @@ func ValidateAtom(atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
 		smoothoperatorvalidation.AddWarning(warnings, *fieldPath, "should not contain atom", atom.GroupVersionKind(), atom.GetName())
 	}
 
-	validateDatasetFeeds(atom, allErrs)
+	validateDatasetFeeds(atom, warnings, allErrs)
+	validateMediaTypes(atom, allErrs)
+	validateLinkHrefs(atom, allErrs)
+	validateInspireThemes(atom.Spec.Service.InspireThemes, field.NewPath("spec").Child("service").Child("inspireThemes"), allErrs)
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		validateInspireThemes(datasetFeed.InspireThemes, field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i).Child("inspireThemes"), allErrs)
+	}
+	validateUpdated(atom, time.Now(), allErrs)
+	validateRefresh(atom, allErrs)
 
 	err := smoothoperatorvalidation.ValidateIngressRouteURLsContainsBaseURL(atom.Spec.IngressRouteURLs, atom.Spec.Service.BaseURL, nil)
 	if err != nil {
@@ -160,7 +282,9 @@ func ValidateAtom(atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
 	}
 }
 
-func validateDatasetFeeds(atom *Atom, allErrs *field.ErrorList) {
+func validateDatasetFeeds(atom *Atom, warnings *[]string, allErrs *field.ErrorList) {
+	validateFeedFileNames(atom, allErrs)
+
 	var feedNames []string
 	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
 		fieldPath := field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i)
@@ -212,5 +336,387 @@ func validateDatasetFeeds(atom *Atom, allErrs *field.ErrorList) {
 
 			entryNames = append(entryNames, entry.TechnicalName)
 		}
+
+		feedPath := field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i)
+		validateBBoxes(atom, datasetFeed, feedPath, warnings, allErrs)
+
+		validateServiceLinks(datasetFeed.ServiceLinks, feedPath.Child("serviceLinks"), allErrs)
+		for j, entry := range datasetFeed.Entries {
+			validateServiceLinks(entry.ServiceLinks, feedPath.Child("entries").Index(j).Child("serviceLinks"), allErrs)
+
+			if entry.Archived && datasetFeed.Archive == nil {
+				*allErrs = append(*allErrs, field.Invalid(
+					feedPath.Child("entries").Index(j).Child("archived"),
+					entry.Archived,
+					fmt.Sprintf("requires %s", feedPath.Child("archive")),
+				))
+			}
+		}
+
+		// Without current entries there is nothing to derive the updated of the dataset feed from
+		if datasetFeed.Archive != nil && len(datasetFeed.GetCurrentEntries()) == 0 && datasetFeed.Updated == nil {
+			*allErrs = append(*allErrs, field.Required(feedPath.Child("updated"), "when all entries are archived"))
+		}
+	}
+}
+
+// validateFeedFileNames checks that the files rendered for the pages and archives of the dataset feeds do not use the file name
+// of a file of another feed, for example page 2 of feed a and a feed with technicalName a-2 both are a-2.xml and a-2.html
+func validateFeedFileNames(atom *Atom, allErrs *field.ErrorList) {
+	servicePath := field.NewPath("spec").Child("service")
+	fileNameOwners := map[string]*DatasetFeed{DCATJSONLDFileName: nil, DCATRDFFileName: nil}
+	fileNamePaths := map[string]*field.Path{DCATJSONLDFileName: servicePath, DCATRDFFileName: servicePath}
+	for _, fileName := range atom.Spec.Service.GetRenderedFileNames("index.xml") {
+		fileNameOwners[fileName], fileNamePaths[fileName] = nil, servicePath
+	}
+
+	for i := range atom.Spec.Service.DatasetFeeds {
+		datasetFeed := &atom.Spec.Service.DatasetFeeds[i]
+		fieldPath := servicePath.Child("datasetFeeds").Index(i)
+		for _, feedFileName := range datasetFeed.GetFeedFileNames() {
+			for _, fileName := range atom.Spec.Service.GetRenderedFileNames(feedFileName) {
+				owner, exists := fileNameOwners[fileName]
+				if !exists {
+					fileNameOwners[fileName], fileNamePaths[fileName] = datasetFeed, fieldPath
+					continue
+				}
+				// Duplicate technical names are reported as such
+				if owner == nil || owner.TechnicalName != datasetFeed.TechnicalName {
+					*allErrs = append(*allErrs, field.Invalid(
+						fieldPath.Child("technicalName"),
+						datasetFeed.TechnicalName,
+						fmt.Sprintf("results in file %s, which is also a file of %s", fileName, fileNamePaths[fileName]),
+					))
+					// The other files of the feed collide as well
+					break
+				}
+			}
+		}
+	}
+}
+
+// validateArchivedEntries warns about entries that move to the archive without a time on their download links,
+// because then the archive does not tell which period the data applies to
+func validateArchivedEntries(atom *Atom, atomOld *Atom, warnings *[]string) {
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		oldFeedIndex := slices.IndexFunc(atomOld.Spec.Service.DatasetFeeds, func(feed DatasetFeed) bool {
+			return feed.TechnicalName == datasetFeed.TechnicalName
+		})
+		if oldFeedIndex < 0 {
+			continue
+		}
+		oldEntries := atomOld.Spec.Service.DatasetFeeds[oldFeedIndex].GetCurrentEntries()
+
+		for j, entry := range datasetFeed.Entries {
+			if !entry.Archived || !slices.ContainsFunc(oldEntries, func(oldEntry Entry) bool { return oldEntry.TechnicalName == entry.TechnicalName }) {
+				continue
+			}
+			if slices.ContainsFunc(entry.DownloadLinks, func(downloadLink DownloadLink) bool { return downloadLink.Time != nil }) {
+				continue
+			}
+
+			fieldPath := field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i).Child("entries").Index(j).Child("archived")
+			smoothoperatorvalidation.AddWarning(warnings, *fieldPath, "entry is moved to the archive without a time on its downloadlinks", atom.GroupVersionKind(), atom.GetName())
+		}
+	}
+}
+
+// validateImages checks that the image overrides come from one of the allowed registries
+func validateImages(atom *Atom, registries []string, allErrs *field.ErrorList) {
+	images := atom.Spec.Images
+	if images == nil {
+		return
+	}
+	fieldPath := field.NewPath("spec").Child("images")
+	if images.AtomGenerator != nil {
+		validateImageRegistry(*images.AtomGenerator, registries, fieldPath.Child("atomGenerator"), allErrs)
+	}
+	if images.Lighttpd != nil {
+		validateImageRegistry(*images.Lighttpd, registries, fieldPath.Child("lighttpd"), allErrs)
+	}
+}
+
+// validateRefresh checks that the feeds are not refreshed more often than MinRefreshInterval
+func validateRefresh(atom *Atom, allErrs *field.ErrorList) {
+	if refresh := atom.Spec.Refresh; refresh != nil && refresh.Interval.Duration < MinRefreshInterval {
+		*allErrs = append(*allErrs, field.Invalid(field.NewPath("spec").Child("refresh").Child("interval"), refresh.Interval.Duration.String(),
+			"should be at least "+MinRefreshInterval.String()))
+	}
+}
+
+func validateImageRegistry(image string, registries []string, fieldPath *field.Path, allErrs *field.ErrorList) {
+	if len(registries) == 0 {
+		*allErrs = append(*allErrs, field.Forbidden(fieldPath, "no image registries are allowed by the operator"))
+		return
+	}
+	for _, registry := range registries {
+		if strings.HasPrefix(image, registry+"/") {
+			return
+		}
 	}
+	*allErrs = append(*allErrs, field.Invalid(fieldPath, image,
+		"should be in one of the allowed registries: "+strings.Join(registries, ", ")))
+}
+
+// validateMediaTypes checks that the types of links and download links are valid media types (RFC 6838), including their parameters
+func validateMediaTypes(atom *Atom, allErrs *field.ErrorList) {
+	servicePath := field.NewPath("spec").Child("service")
+	for i, link := range atom.Spec.Service.Links {
+		validateMediaType(link.Type, servicePath.Child("links").Index(i).Child("type"), allErrs)
+	}
+
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		feedPath := servicePath.Child("datasetFeeds").Index(i)
+		for j, link := range datasetFeed.Links {
+			validateMediaType(link.Type, feedPath.Child("links").Index(j).Child("type"), allErrs)
+		}
+		for j, entry := range datasetFeed.Entries {
+			for k, downloadLink := range entry.DownloadLinks {
+				if downloadLink.Type != nil {
+					validateMediaType(*downloadLink.Type, feedPath.Child("entries").Index(j).Child("downloadlinks").Index(k).Child("type"), allErrs)
+				}
+			}
+		}
+	}
+}
+
+// validateUpdated checks that the updated times of the service, dataset feeds and entries are not in the future,
+// as they end up in the updated elements of the feeds
+func validateUpdated(atom *Atom, now time.Time, allErrs *field.ErrorList) {
+	servicePath := field.NewPath("spec").Child("service")
+	validateNotInFuture := func(updated *metav1.Time, fieldPath *field.Path) {
+		if updated != nil && updated.After(now) {
+			*allErrs = append(*allErrs, field.Invalid(fieldPath, updated.UTC().Format(time.RFC3339), "should not be in the future"))
+		}
+	}
+
+	validateNotInFuture(atom.Spec.Service.Updated, servicePath.Child("updated"))
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		feedPath := servicePath.Child("datasetFeeds").Index(i)
+		validateNotInFuture(datasetFeed.Updated, feedPath.Child("updated"))
+		for j := range datasetFeed.Entries {
+			validateNotInFuture(&datasetFeed.Entries[j].Updated, feedPath.Child("entries").Index(j).Child("updated"))
+		}
+	}
+}
+
+// validateInspireThemes checks that the themes are in the INSPIRE theme register and are not repeated
+func validateInspireThemes(themes []InspireTheme, fieldPath *field.Path, allErrs *field.ErrorList) {
+	var codes []string
+	for i, theme := range themes {
+		code, ok := theme.GetCode()
+		if !ok {
+			*allErrs = append(*allErrs, field.Invalid(fieldPath.Index(i).Child("uri"), theme.URI.String(),
+				fmt.Sprintf("should be a theme of the INSPIRE theme register, for example %s/au", InspireThemeScheme)))
+			continue
+		}
+		if slices.Contains(codes, code) {
+			*allErrs = append(*allErrs, field.Duplicate(fieldPath.Index(i).Child("uri"), theme.URI.String()))
+		}
+		codes = append(codes, code)
+	}
+}
+
+func validateMediaType(mediaType string, fieldPath *field.Path, allErrs *field.ErrorList) {
+	if _, _, err := mime.ParseMediaType(mediaType); err != nil || !strings.Contains(mediaType, "/") {
+		*allErrs = append(*allErrs, field.Invalid(fieldPath, mediaType, "must be a valid media type, for example application/geopackage+sqlite3"))
+	}
+}
+
+// validateServiceLinks checks that the endpoints are absolute URLs, they are linked to directly instead of through the downloads of the Atom
+func validateServiceLinks(serviceLinks []ServiceLink, fieldPath *field.Path, allErrs *field.ErrorList) {
+	for i, serviceLink := range serviceLinks {
+		endpoint := serviceLink.Endpoint.URL
+		if endpoint == nil {
+			*allErrs = append(*allErrs, field.Required(fieldPath.Index(i).Child("endpoint"), "must be an absolute URL"))
+		} else if !endpoint.IsAbs() || endpoint.Host == "" {
+			*allErrs = append(*allErrs, field.Invalid(fieldPath.Index(i).Child("endpoint"), endpoint.String(), "must be an absolute URL"))
+		}
+	}
+}
+
+func validateBBoxes(atom *Atom, datasetFeed DatasetFeed, fieldPath *field.Path, warnings *[]string, allErrs *field.ErrorList) {
+	for i, entry := range datasetFeed.Entries {
+		entryPath := fieldPath.Child("entries").Index(i)
+		code, _ := entry.SRS.GetEPSGCode()
+		plausibleExtent, knownSRS := plausibleExtents[code]
+
+		polygonPath := entryPath.Child("polygon").Child("bbox")
+		polygonExtent, ok := validatePolygon(atom, entry.Polygon, entryPath.Child("polygon"), warnings, allErrs)
+		if ok && knownSRS && !plausibleExtent.contains(polygonExtent) {
+			*allErrs = append(*allErrs, field.Invalid(
+				polygonPath,
+				entry.Polygon.GetBBox().ToExtent(),
+				fmt.Sprintf("is outside the area of EPSG:%d from %s", code, entryPath.Child("srs").Child("uri")),
+			))
+		}
+
+		for j, downloadLink := range entry.DownloadLinks {
+			if downloadLink.BBox == nil {
+				continue
+			}
+
+			downloadPath := entryPath.Child("downloadlinks").Index(j).Child("bbox")
+			downloadExtent, downloadOk := validateBBox(*downloadLink.BBox, downloadPath, allErrs)
+			if !downloadOk {
+				continue
+			}
+
+			if knownSRS && !plausibleExtent.contains(downloadExtent) {
+				*allErrs = append(*allErrs, field.Invalid(
+					downloadPath,
+					downloadLink.BBox.ToExtent(),
+					fmt.Sprintf("is outside the area of EPSG:%d from %s", code, entryPath.Child("srs").Child("uri")),
+				))
+			} else if ok && !polygonExtent.contains(downloadExtent) {
+				smoothoperatorvalidation.AddWarning(warnings, *downloadPath, "falls outside "+polygonPath.String(), atom.GroupVersionKind(), atom.GetName())
+			}
+		}
+	}
+}
+
+// AddLegacyAtomGeneratorWarnings warns about the parts of the Atom that the atom-generator of the --legacy-atom-generator cannot render
+func AddLegacyAtomGeneratorWarnings(atom *Atom, warnings *[]string) {
+	if atom.Spec.Refresh != nil {
+		smoothoperatorvalidation.AddWarning(warnings, *field.NewPath("spec").Child("refresh"),
+			"is ignored, the legacy atom-generator only renders the feeds when a pod starts", atom.GroupVersionKind(), atom.GetName())
+	}
+
+	servicePath := field.NewPath("spec").Child("service")
+	addLegacyCategoriesWarnings(atom, atom.Spec.Service.Keywords, atom.Spec.Service.InspireThemes, servicePath, warnings)
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		addLegacyCategoriesWarnings(atom, datasetFeed.Keywords, datasetFeed.InspireThemes, servicePath.Child("datasetFeeds").Index(i), warnings)
+	}
+
+	for i, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		for j, entry := range datasetFeed.Entries {
+			if entry.Polygon.Geometry == nil {
+				continue
+			}
+			geometry, err := ParseGeometry(*entry.Polygon.Geometry)
+			if err != nil || geometry.IsSimplePolygon() {
+				continue
+			}
+
+			message := "is published without its holes, the legacy atom-generator only supports georss:polygon"
+			if len(geometry) > 1 {
+				message = "is reduced to its bbox, the legacy atom-generator only supports georss:polygon"
+			}
+			fieldPath := field.NewPath("spec").Child("service").Child("datasetFeeds").Index(i).Child("entries").Index(j).Child("polygon").Child("geometry")
+			smoothoperatorvalidation.AddWarning(warnings, *fieldPath, message, atom.GroupVersionKind(), atom.GetName())
+		}
+	}
+}
+
+// AddIgnoredImageWarnings warns about the image overrides of the Atom that have no effect on how the operator serves its feeds.
+// The atom-generator image is only used by the --legacy-atom-generator, the lighttpd image only by a Deployment per Atom.
+func AddIgnoredImageWarnings(atom *Atom, legacyAtomGenerator, sharedServer, publishedFeeds bool, warnings *[]string) {
+	images := atom.Spec.Images
+	if images == nil {
+		return
+	}
+	fieldPath := field.NewPath("spec").Child("images")
+	if images.AtomGenerator != nil && !legacyAtomGenerator {
+		smoothoperatorvalidation.AddWarning(warnings, *fieldPath.Child("atomGenerator"),
+			"is ignored, the feeds are rendered by the operator instead of by the atom-generator", atom.GroupVersionKind(), atom.GetName())
+	}
+	if images.Lighttpd != nil {
+		switch {
+		case sharedServer:
+			smoothoperatorvalidation.AddWarning(warnings, *fieldPath.Child("lighttpd"),
+				"is ignored, the shared server of the namespace runs the lighttpd image of the operator", atom.GroupVersionKind(), atom.GetName())
+		case publishedFeeds:
+			smoothoperatorvalidation.AddWarning(warnings, *fieldPath.Child("lighttpd"),
+				"is ignored, the published feeds are served from blob storage", atom.GroupVersionKind(), atom.GetName())
+		}
+	}
+}
+
+// addLegacyCategoriesWarnings warns that the keywords and INSPIRE themes of a feed are dropped, the legacy atom-generator
+// cannot render the categories of feeds
+func addLegacyCategoriesWarnings(atom *Atom, keywords []string, inspireThemes []InspireTheme, fieldPath *field.Path, warnings *[]string) {
+	message := "are not published, the legacy atom-generator does not support categories of feeds"
+	if len(keywords) > 0 {
+		smoothoperatorvalidation.AddWarning(warnings, *fieldPath.Child("keywords"), message, atom.GroupVersionKind(), atom.GetName())
+	}
+	if len(inspireThemes) > 0 {
+		smoothoperatorvalidation.AddWarning(warnings, *fieldPath.Child("inspireThemes"), message, atom.GroupVersionKind(), atom.GetName())
+	}
+}
+
+// validatePolygon validates the geometry and bbox of the polygon and returns the extent of the polygon.
+// When only the geometry is given the extent of the geometry is used.
+func validatePolygon(atom *Atom, polygon Polygon, fieldPath *field.Path, warnings *[]string, allErrs *field.ErrorList) (extent, bool) {
+	bboxPath := fieldPath.Child("bbox")
+	hasBBox := polygon.BBox != (smoothoperatormodel.BBox{})
+	if polygon.Geometry == nil {
+		if !hasBBox {
+			*allErrs = append(*allErrs, field.Required(fieldPath, "bbox or geometry is required"))
+			return extent{}, false
+		}
+		return validateBBox(polygon.BBox, bboxPath, allErrs)
+	}
+
+	geometryPath := fieldPath.Child("geometry")
+	geometry, err := ParseGeometry(*polygon.Geometry)
+	if err == nil {
+		err = geometry.Validate()
+	}
+	if err != nil {
+		*allErrs = append(*allErrs, field.Invalid(geometryPath, *polygon.Geometry, err.Error()))
+		if hasBBox {
+			return validateBBox(polygon.BBox, bboxPath, allErrs)
+		}
+		return extent{}, false
+	}
+
+	geometryExtent := geometry.extent()
+	if !hasBBox {
+		return geometryExtent, true
+	}
+
+	bboxExtent, ok := validateBBox(polygon.BBox, bboxPath, allErrs)
+	if ok && !bboxExtent.contains(geometryExtent) {
+		smoothoperatorvalidation.AddWarning(warnings, *geometryPath, "falls outside "+bboxPath.String(), atom.GroupVersionKind(), atom.GetName())
+	}
+	return bboxExtent, ok
+}
+
+// validateBBox parses the coordinates of the bbox and checks that the minimum is not larger than the maximum
+func validateBBox(bbox smoothoperatormodel.BBox, fieldPath *field.Path, allErrs *field.ErrorList) (extent, bool) {
+	var result extent
+	valid := true
+	for _, coord := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"minx", bbox.MinX, &result.minX},
+		{"miny", bbox.MinY, &result.minY},
+		{"maxx", bbox.MaxX, &result.maxX},
+		{"maxy", bbox.MaxY, &result.maxY},
+	} {
+		f, err := strconv.ParseFloat(coord.value, 64)
+		if err != nil {
+			*allErrs = append(*allErrs, field.Invalid(fieldPath.Child(coord.name), coord.value, "must be a number"))
+			valid = false
+			continue
+		}
+		*coord.dest = f
+	}
+
+	if !valid {
+		return result, false
+	}
+
+	if result.minX > result.maxX {
+		*allErrs = append(*allErrs, field.Invalid(fieldPath.Child("minx"), bbox.MinX, "should not be larger than maxx "+bbox.MaxX))
+		valid = false
+	}
+	if result.minY > result.maxY {
+		*allErrs = append(*allErrs, field.Invalid(fieldPath.Child("miny"), bbox.MinY, "should not be larger than maxy "+bbox.MaxY))
+		valid = false
+	}
+
+	return result, valid
 }
@@ -0,0 +1,118 @@
+/*
+MIT License
+
+Copyright (c) 2024 Publieke Dienstverlening op de Kaart
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AtomOperatorSettings are settings of the operator that can differ per namespace.
+// Settings that are not set fall back to the flags of the operator.
+type AtomOperatorSettings struct {
+	// Base URL from which the baseUrl of Atoms is derived, overrides --atom-baseurl.
+	// The baseUrl is stored in an Atom when it is created, so a change only applies to Atoms that are created afterwards.
+	// +kubebuilder:validation:Pattern:=`^https?://.+`
+	// +optional
+	BaseURL *string `json:"baseUrl,omitempty"`
+
+	// Endpoint of the blob storage that holds the downloads, overrides --blob-endpoint.
+	// The feeds of the Atoms are rendered again when it changes.
+	// +kubebuilder:validation:Pattern:=`^https?://.+`
+	// +optional
+	BlobEndpoint *string `json:"blobEndpoint,omitempty"`
+
+	// Image of the atom-generator, overrides --atom-generator-image.
+	// The Atoms roll out a new image when it changes.
+	// +kubebuilder:validation:MinLength:=1
+	// +optional
+	AtomGeneratorImage *string `json:"atomGeneratorImage,omitempty"`
+
+	// Image of lighttpd, overrides --lighttpd-image.
+	// The Atoms roll out a new image when it changes.
+	// +kubebuilder:validation:MinLength:=1
+	// +optional
+	LighttpdImage *string `json:"lighttpdImage,omitempty"`
+
+	// Content-Security-Policy header of the feeds, overrides the CSP of the operator.
+	// The headers of the Atoms are updated when it changes.
+	// +kubebuilder:validation:MinLength:=1
+	// +optional
+	CSP *string `json:"csp,omitempty"`
+
+	// Registries, optionally followed by a path, that spec.images of Atoms may use, overrides --allowed-image-registries.
+	// A change applies when Atoms are created or updated, existing Atoms are not validated again.
+	// +optional
+	AllowedImageRegistries []string `json:"allowedImageRegistries,omitempty"`
+}
+
+// NamespaceOverride holds the settings for the Atoms in one namespace
+type NamespaceOverride struct {
+	// +kubebuilder:validation:MinLength:=1
+	Namespace string `json:"namespace"`
+
+	AtomOperatorSettings `json:",inline"`
+}
+
+// AtomOperatorConfigSpec defines the settings of the operator
+type AtomOperatorConfigSpec struct {
+	AtomOperatorSettings `json:",inline"`
+
+	// Webhook URL to which errors are sent to Slack, overrides --slack-webhook-url.
+	// A change applies to the next error.
+	// +optional
+	SlackWebhookURL *string `json:"slackWebhookUrl,omitempty"`
+
+	// Settings that override the settings above for the Atoms in a namespace
+	// +listType=map
+	// +listMapKey=namespace
+	// +optional
+	NamespaceOverrides []NamespaceOverride `json:"namespaceOverrides,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=pdok
+
+// AtomOperatorConfig holds the settings of the atom-operator, changes apply without a restart of the operator.
+// Each setting documents when a change takes effect, the baseUrl of existing Atoms never changes.
+// Only the AtomOperatorConfig with the name given by --operator-config is used.
+type AtomOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AtomOperatorConfigSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// AtomOperatorConfigList contains a list of AtomOperatorConfig.
+type AtomOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AtomOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AtomOperatorConfig{}, &AtomOperatorConfigList{})
+}
@@ -0,0 +1,123 @@
+/*
+MIT License
+
+Copyright (c) 2024 Publieke Dienstverlening op de Kaart
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v3
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cbroglie/mustache"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+)
+
+// The variables of the mustache templates of hrefs. Every templated href has the service variables, hrefs of
+// a dataset feed also have the dataset variables. The identifier is the metadataIdentifier of the
+// serviceMetadataLinks or datasetMetadataLinks, so it is only available when those are set.
+const (
+	// HrefVariableIdentifier is the metadataIdentifier of the metadata links
+	HrefVariableIdentifier = "identifier"
+	// HrefVariableLang is the spec.service.lang of the Atom
+	HrefVariableLang = "lang"
+	// HrefVariableBaseURL is the spec.service.baseUrl of the Atom
+	HrefVariableBaseURL = "baseUrl"
+	// HrefVariableOwnerInfo is the name of the OwnerInfo of the Atom
+	HrefVariableOwnerInfo = "ownerInfo"
+	// HrefVariableTechnicalName is the technicalName of the dataset feed
+	HrefVariableTechnicalName = "technicalName"
+	// HrefVariableSpatialDatasetIdentifierCode is the spatialDatasetIdentifierCode of the dataset feed, empty when not set
+	HrefVariableSpatialDatasetIdentifierCode = "spatialDatasetIdentifierCode"
+	// HrefVariableSpatialDatasetIdentifierNamespace is the spatialDatasetIdentifierNamespace of the dataset feed, empty when not set
+	HrefVariableSpatialDatasetIdentifierNamespace = "spatialDatasetIdentifierNamespace"
+)
+
+// GetServiceHrefVariables returns the variables of templated hrefs of the service feed
+func GetServiceHrefVariables(atom *Atom) map[string]string {
+	variables := map[string]string{
+		HrefVariableLang:      atom.Spec.Service.Lang,
+		HrefVariableBaseURL:   atom.Spec.Service.BaseURL.String(),
+		HrefVariableOwnerInfo: atom.Spec.Service.OwnerInfoRef,
+	}
+	if metadataLinks := atom.Spec.Service.ServiceMetadataLinks; metadataLinks != nil {
+		variables[HrefVariableIdentifier] = metadataLinks.MetadataIdentifier
+	}
+	return variables
+}
+
+// GetDatasetHrefVariables returns the variables of templated hrefs of the dataset feed and its entry in the service feed
+func GetDatasetHrefVariables(atom *Atom, datasetFeed DatasetFeed) map[string]string {
+	variables := GetServiceHrefVariables(atom)
+	delete(variables, HrefVariableIdentifier)
+	if metadataLinks := datasetFeed.DatasetMetadataLinks; metadataLinks != nil {
+		variables[HrefVariableIdentifier] = metadataLinks.MetadataIdentifier
+	}
+	variables[HrefVariableTechnicalName] = datasetFeed.TechnicalName
+	variables[HrefVariableSpatialDatasetIdentifierCode] = ""
+	if datasetFeed.SpatialDatasetIdentifierCode != nil {
+		variables[HrefVariableSpatialDatasetIdentifierCode] = *datasetFeed.SpatialDatasetIdentifierCode
+	}
+	variables[HrefVariableSpatialDatasetIdentifierNamespace] = ""
+	if datasetFeed.SpatialDatasetIdentifierNamespace != nil {
+		variables[HrefVariableSpatialDatasetIdentifierNamespace] = *datasetFeed.SpatialDatasetIdentifierNamespace
+	}
+	return variables
+}
+
+// GetHrefTemplate returns the href of a link as template. The URL escapes the braces of variables in its path.
+func GetHrefTemplate(href smoothoperatormodel.URL) string {
+	return strings.NewReplacer("%7B", "{", "%7D", "}", "%7b", "{", "%7d", "}").Replace(href.String())
+}
+
+// RenderHrefTemplate renders the mustache template of an href. Variables that are not available are an error,
+// instead of an empty string.
+func RenderHrefTemplate(hrefTemplate string, variables map[string]string) (string, error) {
+	template, err := mustache.ParseString(hrefTemplate)
+	if err != nil {
+		return "", fmt.Errorf("href template %s is not a valid mustache template: %w", hrefTemplate, err)
+	}
+	if err := checkHrefTemplateTags(template.Tags(), variables); err != nil {
+		return "", fmt.Errorf("href template %s: %w", hrefTemplate, err)
+	}
+	return template.Render(variables)
+}
+
+func checkHrefTemplateTags(tags []mustache.Tag, variables map[string]string) error {
+	for _, tag := range tags {
+		if tag.Type() == mustache.Partial {
+			return fmt.Errorf("partial %s is not supported", tag.Name())
+		}
+		if _, ok := variables[tag.Name()]; !ok {
+			return fmt.Errorf("unknown variable %s, available are %s", tag.Name(), strings.Join(slices.Sorted(maps.Keys(variables)), ", "))
+		}
+		if tag.Type() == mustache.Variable {
+			continue
+		}
+		if err := checkHrefTemplateTags(tag.Tags(), variables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
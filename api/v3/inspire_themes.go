@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2024 Publieke Dienstverlening op de Kaart
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v3
+
+import (
+	"strings"
+)
+
+// InspireThemeScheme is the INSPIRE theme register, the URIs of the themes are relative to it
+const InspireThemeScheme = "http://inspire.ec.europa.eu/theme"
+
+// inspireThemes are the English labels of the themes of the INSPIRE theme register by code
+var inspireThemes = map[string]string{
+	"ac": "Atmospheric conditions",
+	"ad": "Addresses",
+	"af": "Agricultural and aquaculture facilities",
+	"am": "Area management/restriction/regulation zones and reporting units",
+	"au": "Administrative units",
+	"br": "Bio-geographical regions",
+	"bu": "Buildings",
+	"cp": "Cadastral parcels",
+	"ef": "Environmental monitoring facilities",
+	"el": "Elevation",
+	"er": "Energy resources",
+	"ge": "Geology",
+	"gg": "Geographical grid systems",
+	"gn": "Geographical names",
+	"hb": "Habitats and biotopes",
+	"hh": "Human health and safety",
+	"hy": "Hydrography",
+	"lc": "Land cover",
+	"lu": "Land use",
+	"mf": "Meteorological geographical features",
+	"mr": "Mineral resources",
+	"nz": "Natural risk zones",
+	"of": "Oceanographic geographical features",
+	"oi": "Orthoimagery",
+	"pd": "Population distribution — demography",
+	"pf": "Production and industrial facilities",
+	"ps": "Protected sites",
+	"rs": "Coordinate reference systems",
+	"sd": "Species distribution",
+	"so": "Soil",
+	"sr": "Sea regions",
+	"su": "Statistical units",
+	"tn": "Transport networks",
+	"us": "Utility and governmental services",
+}
+
+// GetCode returns the code of the theme in the INSPIRE theme register, for example au,
+// which is false when the URI is not a theme of the register
+func (theme *InspireTheme) GetCode() (string, bool) {
+	code, ok := strings.CutPrefix(strings.TrimSuffix(theme.URI.String(), "/"), InspireThemeScheme+"/")
+	if !ok {
+		return "", false
+	}
+	_, ok = inspireThemes[code]
+	return code, ok
+}
+
+// GetLabel returns the label of the theme, which is the English label of the register when not set
+func (theme *InspireTheme) GetLabel() string {
+	if theme.Label != nil {
+		return *theme.Label
+	}
+	code, _ := theme.GetCode()
+	return inspireThemes[code]
+}
@@ -0,0 +1,218 @@
+/*
+MIT License
+
+Copyright (c) 2024 Publieke Dienstverlening op de Kaart
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cbroglie/mustache"
+	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// MetadataLinkTemplatesAnnotation holds the custom metadata link templates of an OwnerInfo as a JSON or YAML list.
+// The OwnerInfo CRD is owned by the smooth-operator, so they cannot be part of its spec.
+const MetadataLinkTemplatesAnnotation = "pdok.nl/atom-metadata-link-templates"
+
+// builtinMetadataLinkTemplates are the templates for the spec.metadataUrls of an OwnerInfo
+var builtinMetadataLinkTemplates = []string{"csw", "opensearch", "html"}
+
+var metadataLinkTemplateNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// MetadataLinkContext is a place in the feeds where the links of a MetadataLink are added
+type MetadataLinkContext string
+
+const (
+	// MetadataLinkContextService are the links of the service feed, from spec.service.serviceMetadataLinks
+	MetadataLinkContextService MetadataLinkContext = "service"
+	// MetadataLinkContextDatasetEntry are the links of the entry of a dataset feed in the service feed
+	MetadataLinkContextDatasetEntry MetadataLinkContext = "datasetEntry"
+	// MetadataLinkContextDatasetFeed are the links of the dataset feed itself
+	MetadataLinkContextDatasetFeed MetadataLinkContext = "datasetFeed"
+)
+
+var metadataLinkContexts = []MetadataLinkContext{MetadataLinkContextService, MetadataLinkContextDatasetEntry, MetadataLinkContextDatasetFeed}
+
+// MetadataLinkTemplate is a named template for the links to the metadata of the service or a dataset
+type MetadataLinkTemplate struct {
+	// Name the MetadataLink templates refer to
+	Name string `json:"name"`
+
+	// Relation of the link, for example describedby
+	Rel string `json:"rel"`
+
+	// Media type of the link, for example application/xml
+	Type string `json:"type"`
+
+	// Optional title of the link
+	Title *string `json:"title,omitempty"`
+
+	// Mustache template of the href, {{identifier}} is replaced by the metadataIdentifier. See the Href* constants for the
+	// other variables.
+	HrefTemplate string `json:"hrefTemplate"`
+
+	// Contexts in which the link is added
+	Contexts []MetadataLinkContext `json:"contexts"`
+}
+
+// AppliesTo returns whether the links of the template are added in the context
+func (t MetadataLinkTemplate) AppliesTo(context MetadataLinkContext) bool {
+	return slices.Contains(t.Contexts, context)
+}
+
+// GetMetadataLinkTemplate returns the metadata link template of the OwnerInfo with the name, or nil when it is not defined.
+// The csw, opensearch and html templates come from its spec.metadataUrls, the others from its MetadataLinkTemplatesAnnotation.
+// Only the template with the name is validated, so a broken custom template does not affect Atoms that do not use it.
+func GetMetadataLinkTemplate(ownerInfo *smoothoperatorv1.OwnerInfo, name string) (*MetadataLinkTemplate, error) {
+	if slices.Contains(builtinMetadataLinkTemplates, name) {
+		return getBuiltinMetadataLinkTemplate(ownerInfo, name)
+	}
+	return getCustomMetadataLinkTemplate(ownerInfo, name)
+}
+
+func getBuiltinMetadataLinkTemplate(ownerInfo *smoothoperatorv1.OwnerInfo, name string) (*MetadataLinkTemplate, error) {
+	if rawTemplates, err := getRawMetadataLinkTemplates(ownerInfo, name); err == nil && len(rawTemplates) > 0 {
+		return nil, fmt.Errorf("annotation %s of OwnerInfo %s is invalid: name %s is already used by a built in template",
+			MetadataLinkTemplatesAnnotation, ownerInfo.Name, name)
+	}
+
+	metadataUrls := ownerInfo.Spec.MetadataUrls
+	if metadataUrls == nil {
+		return nil, nil
+	}
+	switch {
+	case name == "csw" && metadataUrls.CSW != nil:
+		return &MetadataLinkTemplate{
+			Name:         "csw",
+			Rel:          "describedby",
+			Type:         "application/xml",
+			HrefTemplate: metadataUrls.CSW.HrefTemplate,
+			Contexts:     metadataLinkContexts,
+		}, nil
+	case name == "opensearch" && metadataUrls.OpenSearch != nil:
+		title := "Open Search document voor INSPIRE Download service PDOK"
+		return &MetadataLinkTemplate{
+			Name:         "opensearch",
+			Rel:          "search",
+			Type:         "application/opensearchdescription+xml",
+			Title:        &title,
+			HrefTemplate: metadataUrls.OpenSearch.HrefTemplate,
+			Contexts:     []MetadataLinkContext{MetadataLinkContextService, MetadataLinkContextDatasetFeed},
+		}, nil
+	case name == "html" && metadataUrls.HTML != nil:
+		return &MetadataLinkTemplate{
+			Name:         "html",
+			Rel:          "describedby",
+			Type:         "text/html",
+			HrefTemplate: metadataUrls.HTML.HrefTemplate,
+			Contexts:     []MetadataLinkContext{MetadataLinkContextService, MetadataLinkContextDatasetFeed},
+		}, nil
+	}
+	return nil, nil
+}
+
+func getCustomMetadataLinkTemplate(ownerInfo *smoothoperatorv1.OwnerInfo, name string) (*MetadataLinkTemplate, error) {
+	rawTemplates, err := getRawMetadataLinkTemplates(ownerInfo, name)
+	if err != nil {
+		return nil, err
+	}
+	switch len(rawTemplates) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("annotation %s of OwnerInfo %s is invalid: name %s is used by %d templates",
+			MetadataLinkTemplatesAnnotation, ownerInfo.Name, name, len(rawTemplates))
+	}
+
+	var template MetadataLinkTemplate
+	if err := yaml.UnmarshalStrict(rawTemplates[0], &template); err != nil {
+		return nil, fmt.Errorf("template %s in annotation %s of OwnerInfo %s is not a metadata link template: %w",
+			name, MetadataLinkTemplatesAnnotation, ownerInfo.Name, err)
+	}
+	if err := validateMetadataLinkTemplate(template); err != nil {
+		return nil, fmt.Errorf("template %s in annotation %s of OwnerInfo %s is invalid: %w",
+			name, MetadataLinkTemplatesAnnotation, ownerInfo.Name, err)
+	}
+	return &template, nil
+}
+
+// getRawMetadataLinkTemplates returns the unparsed custom templates with the name, so the other templates in the annotation
+// are not validated
+func getRawMetadataLinkTemplates(ownerInfo *smoothoperatorv1.OwnerInfo, name string) ([]json.RawMessage, error) {
+	annotation, ok := ownerInfo.GetAnnotations()[MetadataLinkTemplatesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var rawTemplates []json.RawMessage
+	if err := yaml.Unmarshal([]byte(annotation), &rawTemplates); err != nil {
+		return nil, fmt.Errorf("annotation %s of OwnerInfo %s is not a list of metadata link templates: %w", MetadataLinkTemplatesAnnotation, ownerInfo.Name, err)
+	}
+
+	var named []json.RawMessage
+	for _, rawTemplate := range rawTemplates {
+		var template struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(rawTemplate, &template); err == nil && template.Name == name {
+			named = append(named, rawTemplate)
+		}
+	}
+	return named, nil
+}
+
+func validateMetadataLinkTemplate(template MetadataLinkTemplate) error {
+	var errs []error
+	if !metadataLinkTemplateNameRegex.MatchString(template.Name) {
+		errs = append(errs, fmt.Errorf("name %q should consist of lower case letters, digits and dashes", template.Name))
+	}
+	if template.Rel == "" {
+		errs = append(errs, errors.New("rel is required"))
+	}
+	if _, _, err := mime.ParseMediaType(template.Type); err != nil || !strings.Contains(template.Type, "/") {
+		errs = append(errs, fmt.Errorf("type %q is not a valid media type", template.Type))
+	}
+	if template.HrefTemplate == "" {
+		errs = append(errs, errors.New("hrefTemplate is required"))
+	} else if _, err := mustache.ParseString(template.HrefTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("hrefTemplate is not a valid mustache template: %w", err))
+	}
+	if len(template.Contexts) == 0 {
+		errs = append(errs, errors.New("contexts is required"))
+	}
+	for _, context := range template.Contexts {
+		if !slices.Contains(metadataLinkContexts, context) {
+			errs = append(errs, fmt.Errorf("context %q should be one of service, datasetEntry or datasetFeed", context))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+/*
+MIT License
+
+Copyright (c) 2024 Publieke Dienstverlening op de Kaart
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperatorSettings are the settings of the operator that apply to the Atoms in a namespace.
+// The images and CSP are empty unless they are set by the AtomOperatorConfig, the reconciler holds their defaults.
+type OperatorSettings struct {
+	BaseURL                string
+	BlobEndpoint           string
+	AtomGeneratorImage     string
+	LighttpdImage          string
+	CSP                    string
+	AllowedImageRegistries []string
+}
+
+// SettingsReader reads the settings of the operator. The AtomOperatorConfig is read on every call, so every replica
+// of the operator uses the current config, also the replicas that only serve the webhooks.
+type SettingsReader struct {
+	// Reader reads the AtomOperatorConfig, which is the cache of the manager. Only the flags apply when nil.
+	Reader client.Reader
+	// ConfigName is the name of the AtomOperatorConfig
+	ConfigName string
+	// Flags are the settings given by the flags of the operator
+	Flags OperatorSettings
+	// SlackWebhookURL is the Slack webhook URL given by the flags
+	SlackWebhookURL string
+}
+
+// GetOperatorConfig returns the spec of the AtomOperatorConfig, nil when there is none
+func (s *SettingsReader) GetOperatorConfig(ctx context.Context) (*AtomOperatorConfigSpec, error) {
+	if s == nil || s.Reader == nil {
+		return nil, nil
+	}
+	operatorConfig := &AtomOperatorConfig{}
+	if err := s.Reader.Get(ctx, client.ObjectKey{Name: s.ConfigName}, operatorConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get atom operator config %s: %w", s.ConfigName, err)
+	}
+	return &operatorConfig.Spec, nil
+}
+
+// GetSettings returns the settings for the Atoms in the namespace, which are empty when s is nil
+func (s *SettingsReader) GetSettings(ctx context.Context, namespace string) (OperatorSettings, error) {
+	if s == nil {
+		return OperatorSettings{}, nil
+	}
+	operatorConfig, err := s.GetOperatorConfig(ctx)
+	if err != nil {
+		return OperatorSettings{}, err
+	}
+	return GetSettings(s.Flags, operatorConfig, namespace), nil
+}
+
+// GetSlackWebhookURL returns the Slack webhook URL of the AtomOperatorConfig, or else the one given by the flags
+func (s *SettingsReader) GetSlackWebhookURL(ctx context.Context) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	operatorConfig, err := s.GetOperatorConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if operatorConfig != nil && operatorConfig.SlackWebhookURL != nil {
+		return *operatorConfig.SlackWebhookURL, nil
+	}
+	return s.SlackWebhookURL, nil
+}
+
+// GetSettings returns the settings for the Atoms in the namespace, operatorConfig is nil when there is no AtomOperatorConfig.
+// The settings of the namespace override the settings of the AtomOperatorConfig, which override the flags.
+func GetSettings(flags OperatorSettings, operatorConfig *AtomOperatorConfigSpec, namespace string) OperatorSettings {
+	namespaceSettings := flags
+	namespaceSettings.BaseURL = strings.TrimSuffix(namespaceSettings.BaseURL, "/")
+	namespaceSettings.AllowedImageRegistries = normaliseImageRegistries(namespaceSettings.AllowedImageRegistries)
+	if operatorConfig == nil {
+		return namespaceSettings
+	}
+	namespaceSettings.apply(&operatorConfig.AtomOperatorSettings)
+	for _, override := range operatorConfig.NamespaceOverrides {
+		if override.Namespace == namespace {
+			namespaceSettings.apply(&override.AtomOperatorSettings)
+		}
+	}
+	return namespaceSettings
+}
+
+func (s *OperatorSettings) apply(settings *AtomOperatorSettings) {
+	if settings.BaseURL != nil {
+		s.BaseURL = strings.TrimSuffix(*settings.BaseURL, "/")
+	}
+	if settings.BlobEndpoint != nil {
+		s.BlobEndpoint = *settings.BlobEndpoint
+	}
+	if settings.AtomGeneratorImage != nil {
+		s.AtomGeneratorImage = *settings.AtomGeneratorImage
+	}
+	if settings.LighttpdImage != nil {
+		s.LighttpdImage = *settings.LighttpdImage
+	}
+	if settings.CSP != nil {
+		s.CSP = *settings.CSP
+	}
+	if settings.AllowedImageRegistries != nil {
+		s.AllowedImageRegistries = normaliseImageRegistries(settings.AllowedImageRegistries)
+	}
+}
+
+func normaliseImageRegistries(registries []string) []string {
+	var normalised []string
+	for _, registry := range registries {
+		if registry = strings.Trim(strings.TrimSpace(registry), "/"); registry != "" {
+			normalised = append(normalised, registry)
+		}
+	}
+	return normalised
+}
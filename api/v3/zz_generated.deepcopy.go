@@ -200,6 +200,26 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Archive) DeepCopyInto(out *Archive) {
+	*out = *in
+	if in.Title != nil {
+		in, out := &in.Title, &out.Title
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Archive.
+func (in *Archive) DeepCopy() *Archive {
+	if in == nil {
+		return nil
+	}
+	out := new(Archive)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Atom) DeepCopyInto(out *Atom) {
 	*out = *in
@@ -259,6 +279,137 @@ func (in *AtomList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AtomOperatorConfig) DeepCopyInto(out *AtomOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomOperatorConfig.
+func (in *AtomOperatorConfig) DeepCopy() *AtomOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AtomOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AtomOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AtomOperatorConfigList) DeepCopyInto(out *AtomOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AtomOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomOperatorConfigList.
+func (in *AtomOperatorConfigList) DeepCopy() *AtomOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(AtomOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AtomOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AtomOperatorConfigSpec) DeepCopyInto(out *AtomOperatorConfigSpec) {
+	*out = *in
+	in.AtomOperatorSettings.DeepCopyInto(&out.AtomOperatorSettings)
+	if in.SlackWebhookURL != nil {
+		in, out := &in.SlackWebhookURL, &out.SlackWebhookURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.NamespaceOverrides != nil {
+		in, out := &in.NamespaceOverrides, &out.NamespaceOverrides
+		*out = make([]NamespaceOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomOperatorConfigSpec.
+func (in *AtomOperatorConfigSpec) DeepCopy() *AtomOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AtomOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AtomOperatorSettings) DeepCopyInto(out *AtomOperatorSettings) {
+	*out = *in
+	if in.BaseURL != nil {
+		in, out := &in.BaseURL, &out.BaseURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.BlobEndpoint != nil {
+		in, out := &in.BlobEndpoint, &out.BlobEndpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.AtomGeneratorImage != nil {
+		in, out := &in.AtomGeneratorImage, &out.AtomGeneratorImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.LighttpdImage != nil {
+		in, out := &in.LighttpdImage, &out.LighttpdImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.CSP != nil {
+		in, out := &in.CSP, &out.CSP
+		*out = new(string)
+		**out = **in
+	}
+	if in.AllowedImageRegistries != nil {
+		in, out := &in.AllowedImageRegistries, &out.AllowedImageRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomOperatorSettings.
+func (in *AtomOperatorSettings) DeepCopy() *AtomOperatorSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AtomOperatorSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AtomSpec) DeepCopyInto(out *AtomSpec) {
 	*out = *in
@@ -275,6 +426,16 @@ func (in *AtomSpec) DeepCopyInto(out *AtomSpec) {
 		}
 	}
 	in.Service.DeepCopyInto(&out.Service)
+	if in.Refresh != nil {
+		in, out := &in.Refresh, &out.Refresh
+		*out = new(Refresh)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = new(Images)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomSpec.
@@ -287,6 +448,47 @@ func (in *AtomSpec) DeepCopy() *AtomSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AtomStatus) DeepCopyInto(out *AtomStatus) {
+	*out = *in
+	in.OperatorStatus.DeepCopyInto(&out.OperatorStatus)
+	if in.Refresh != nil {
+		in, out := &in.Refresh, &out.Refresh
+		*out = new(RefreshStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ContainerImage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AtomStatus.
+func (in *AtomStatus) DeepCopy() *AtomStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AtomStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerImage) DeepCopyInto(out *ContainerImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerImage.
+func (in *ContainerImage) DeepCopy() *ContainerImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatasetFeed) DeepCopyInto(out *DatasetFeed) {
 	*out = *in
@@ -303,6 +505,27 @@ func (in *DatasetFeed) DeepCopyInto(out *DatasetFeed) {
 		}
 	}
 	out.Author = in.Author
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(License)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Updated != nil {
+		in, out := &in.Updated, &out.Updated
+		*out = (*in).DeepCopy()
+	}
+	if in.Keywords != nil {
+		in, out := &in.Keywords, &out.Keywords
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InspireThemes != nil {
+		in, out := &in.InspireThemes, &out.InspireThemes
+		*out = make([]InspireTheme, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SpatialDatasetIdentifierCode != nil {
 		in, out := &in.SpatialDatasetIdentifierCode, &out.SpatialDatasetIdentifierCode
 		*out = new(string)
@@ -313,6 +536,13 @@ func (in *DatasetFeed) DeepCopyInto(out *DatasetFeed) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ServiceLinks != nil {
+		in, out := &in.ServiceLinks, &out.ServiceLinks
+		*out = make([]ServiceLink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Entries != nil {
 		in, out := &in.Entries, &out.Entries
 		*out = make([]Entry, len(*in))
@@ -320,6 +550,16 @@ func (in *DatasetFeed) DeepCopyInto(out *DatasetFeed) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(Archive)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PageSize != nil {
+		in, out := &in.PageSize, &out.PageSize
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetFeed.
@@ -350,6 +590,16 @@ func (in *DownloadLink) DeepCopyInto(out *DownloadLink) {
 		*out = new(model.BBox)
 		**out = **in
 	}
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.Length != nil {
+		in, out := &in.Length, &out.Length
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadLink.
@@ -382,8 +632,20 @@ func (in *Entry) DeepCopyInto(out *Entry) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ServiceLinks != nil {
+		in, out := &in.ServiceLinks, &out.ServiceLinks
+		*out = make([]ServiceLink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Updated.DeepCopyInto(&out.Updated)
-	out.Polygon = in.Polygon
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	in.Polygon.DeepCopyInto(&out.Polygon)
 	in.SRS.DeepCopyInto(&out.SRS)
 }
 
@@ -397,6 +659,73 @@ func (in *Entry) DeepCopy() *Entry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Images) DeepCopyInto(out *Images) {
+	*out = *in
+	if in.AtomGenerator != nil {
+		in, out := &in.AtomGenerator, &out.AtomGenerator
+		*out = new(string)
+		**out = **in
+	}
+	if in.Lighttpd != nil {
+		in, out := &in.Lighttpd, &out.Lighttpd
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Images.
+func (in *Images) DeepCopy() *Images {
+	if in == nil {
+		return nil
+	}
+	out := new(Images)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InspireTheme) DeepCopyInto(out *InspireTheme) {
+	*out = *in
+	in.URI.DeepCopyInto(&out.URI)
+	if in.Label != nil {
+		in, out := &in.Label, &out.Label
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InspireTheme.
+func (in *InspireTheme) DeepCopy() *InspireTheme {
+	if in == nil {
+		return nil
+	}
+	out := new(InspireTheme)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *License) DeepCopyInto(out *License) {
+	*out = *in
+	in.URI.DeepCopyInto(&out.URI)
+	if in.Attribution != nil {
+		in, out := &in.Attribution, &out.Attribution
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new License.
+func (in *License) DeepCopy() *License {
+	if in == nil {
+		return nil
+	}
+	out := new(License)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Link) DeepCopyInto(out *Link) {
 	*out = *in
@@ -443,10 +772,76 @@ func (in *MetadataLink) DeepCopy() *MetadataLink {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataLinkTemplate) DeepCopyInto(out *MetadataLinkTemplate) {
+	*out = *in
+	if in.Title != nil {
+		in, out := &in.Title, &out.Title
+		*out = new(string)
+		**out = **in
+	}
+	if in.Contexts != nil {
+		in, out := &in.Contexts, &out.Contexts
+		*out = make([]MetadataLinkContext, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataLinkTemplate.
+func (in *MetadataLinkTemplate) DeepCopy() *MetadataLinkTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataLinkTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceOverride) DeepCopyInto(out *NamespaceOverride) {
+	*out = *in
+	in.AtomOperatorSettings.DeepCopyInto(&out.AtomOperatorSettings)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceOverride.
+func (in *NamespaceOverride) DeepCopy() *NamespaceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorSettings) DeepCopyInto(out *OperatorSettings) {
+	*out = *in
+	if in.AllowedImageRegistries != nil {
+		in, out := &in.AllowedImageRegistries, &out.AllowedImageRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorSettings.
+func (in *OperatorSettings) DeepCopy() *OperatorSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Polygon) DeepCopyInto(out *Polygon) {
 	*out = *in
 	out.BBox = in.BBox
+	if in.Geometry != nil {
+		in, out := &in.Geometry, &out.Geometry
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Polygon.
@@ -459,6 +854,38 @@ func (in *Polygon) DeepCopy() *Polygon {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Refresh) DeepCopyInto(out *Refresh) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Refresh.
+func (in *Refresh) DeepCopy() *Refresh {
+	if in == nil {
+		return nil
+	}
+	out := new(Refresh)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RefreshStatus) DeepCopyInto(out *RefreshStatus) {
+	*out = *in
+	in.LastRefreshTime.DeepCopyInto(&out.LastRefreshTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RefreshStatus.
+func (in *RefreshStatus) DeepCopy() *RefreshStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RefreshStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SRS) DeepCopyInto(out *SRS) {
 	*out = *in
@@ -495,6 +922,27 @@ func (in *Service) DeepCopyInto(out *Service) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(License)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Updated != nil {
+		in, out := &in.Updated, &out.Updated
+		*out = (*in).DeepCopy()
+	}
+	if in.Keywords != nil {
+		in, out := &in.Keywords, &out.Keywords
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InspireThemes != nil {
+		in, out := &in.InspireThemes, &out.InspireThemes
+		*out = make([]InspireTheme, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.DatasetFeeds != nil {
 		in, out := &in.DatasetFeeds, &out.DatasetFeeds
 		*out = make([]DatasetFeed, len(*in))
@@ -513,3 +961,24 @@ func (in *Service) DeepCopy() *Service {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLink) DeepCopyInto(out *ServiceLink) {
+	*out = *in
+	in.Endpoint.DeepCopyInto(&out.Endpoint)
+	if in.Title != nil {
+		in, out := &in.Title, &out.Title
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLink.
+func (in *ServiceLink) DeepCopy() *ServiceLink {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLink)
+	in.DeepCopyInto(out)
+	return out
+}
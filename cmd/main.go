@@ -17,16 +17,22 @@ limitations under the License.
 package main
 
 import (
+	"cmp"
+	"context"
 	"crypto/tls"
 	"errors"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/go-logr/zapr"
 	"github.com/pdok/smooth-operator/pkg/integrations/logging"
+	"github.com/pdok/smooth-operator/pkg/integrations/slack"
 	"github.com/peterbourgon/ff"
+	uberzap "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -39,12 +45,16 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
 	"github.com/pdok/atom-operator/internal/controller"
+	"github.com/pdok/atom-operator/internal/controller/blobstorage"
+	"github.com/pdok/atom-operator/internal/controller/generator"
 	webhookpdoknlv3 "github.com/pdok/atom-operator/internal/webhook/v3"
 	// +kubebuilder:scaffold:imports
 )
@@ -54,6 +64,9 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// slackSettingsTimeout limits the time to read the Slack webhook URL of the AtomOperatorConfig for an error
+const slackSettingsTimeout = 5 * time.Second
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -80,6 +93,14 @@ func main() {
 	var slackWebhookURL string
 	var logLevel int
 	var csp string
+	var legacyAtomGenerator bool
+	var sharedServer bool
+	var feedsContainer string
+	var azureStorageConnectionString string
+	var refreshInterval time.Duration
+	var allowedImageRegistries string
+	var maxConcurrentImageRollouts int
+	var operatorConfigName string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -95,6 +116,23 @@ func main() {
 	flag.StringVar(&baseURL, "atom-baseurl", "", "The base url which is used in the atom service.")
 	flag.StringVar(&blobEndpoint, "blob-endpoint", "", "The blobstore endpoint used for file downloads.")
 	flag.StringVar(&atomGeneratorImage, "atom-generator-image", "", "The image to use in the Atom generator init-container.")
+	flag.BoolVar(&legacyAtomGenerator, "legacy-atom-generator", false,
+		"If set, the feeds are generated by an init-container with the --atom-generator-image instead of by the operator.")
+	flag.BoolVar(&sharedServer, "shared-server", false,
+		"If set, the Atoms in a namespace are served by one shared Deployment instead of a Deployment per Atom.")
+	flag.StringVar(&feedsContainer, "feeds-container", "",
+		"If set, the feeds are published to this blob container, optionally followed by a prefix (container/prefix), instead of being served by pods.")
+	flag.StringVar(&azureStorageConnectionString, "azure-storage-connection-string", "", "The connection string of the blob storage the feeds are published to.")
+	flag.DurationVar(&refreshInterval, "refresh-interval", 0,
+		"If set, the feeds of every Atom are rendered again at this interval and rolled out when they changed. An Atom can override this with spec.refresh.")
+	flag.StringVar(&allowedImageRegistries, "allowed-image-registries", "",
+		"Comma separated registries, optionally followed by a path (registry/path), that Atoms may override the images with. Overrides are rejected if empty.")
+	flag.IntVar(&maxConcurrentImageRollouts, "max-concurrent-image-rollouts", 0,
+		"If set, at most this many Atoms roll out a new --lighttpd-image or --atom-generator-image at the same time, "+
+			"in the order of their pdok.nl/rollout-priority label. A shared server counts as one Atom. Disabled if 0.")
+	flag.StringVar(&operatorConfigName, "operator-config", "atom-operator",
+		"The name of the cluster-scoped AtomOperatorConfig whose settings override the flags. "+
+			"Changes apply without a restart, but existing Atoms keep the baseUrl they were created with.")
 	flag.StringVar(&lighttpdImage, "lighttpd-image", "", "The image to use in the Atom pod.")
 	flag.StringVar(&slackWebhookURL, "slack-webhook-url", "", "The webhook url for sending slack messages. Disabled if left empty")
 	flag.IntVar(&logLevel, "log-level", 0, "The zapcore loglevel. 0 = info, 1 = warn, 2 = error")
@@ -113,27 +151,57 @@ func main() {
 
 	//nolint:gosec
 	levelEnabler := zapcore.Level(logLevel)
-	zapLogger, _ := logging.SetupLogger("atom-operator", slackWebhookURL, levelEnabler)
+	// The settings read the AtomOperatorConfig from the cache of the manager once it is created
+	settings := &pdoknlv3.SettingsReader{
+		ConfigName: operatorConfigName,
+		Flags: pdoknlv3.OperatorSettings{
+			BaseURL:                baseURL,
+			BlobEndpoint:           blobEndpoint,
+			AllowedImageRegistries: strings.Split(allowedImageRegistries, ","),
+		},
+		SlackWebhookURL: slackWebhookURL,
+	}
+
+	// The Slack webhook URL can change with the AtomOperatorConfig, so errors are sent to Slack by a core that reads it on every write
+	zapLogger, _ := logging.SetupLogger("atom-operator", "", levelEnabler)
+	zapLogger = zapLogger.WithOptions(uberzap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, newSlackCore("atom-operator", settings))
+	}))
 	logrLogger := zapr.NewLogger(zapLogger)
 
 	ctrl.SetLogger(logrLogger)
 
-	reqFlags := make(map[string]string)
-	reqFlags["baseurl"] = baseURL
-	reqFlags["blobEndpoint"] = blobEndpoint
-	reqFlags["atom-generator-image"] = atomGeneratorImage
-	reqFlags["lighttpd-image"] = lighttpdImage
+	if legacyAtomGenerator && sharedServer {
+		setupLog.Error(errors.New("legacy-atom-generator and shared-server cannot be combined"), "The shared server only serves feeds that are generated by the operator.")
+		os.Exit(1)
+	}
 
-	for reqFlag, val := range reqFlags {
-		if val == "" {
-			setupLog.Error(errors.New(reqFlag+" is a required flag"), "A value for "+reqFlag+" must be specified.")
-			os.Exit(1)
-		}
+	if refreshInterval > 0 && refreshInterval < pdoknlv3.MinRefreshInterval {
+		setupLog.Error(errors.New("refresh-interval is too short"), "The refresh-interval should be at least "+pdoknlv3.MinRefreshInterval.String()+".")
+		os.Exit(1)
 	}
 
-	pdoknlv3.SetBaseURL(baseURL)
+	if legacyAtomGenerator && refreshInterval > 0 {
+		setupLog.Error(errors.New("legacy-atom-generator and refresh-interval cannot be combined"), "The atom-generator init-container only renders the feeds when a pod starts.")
+		os.Exit(1)
+	}
 
-	pdoknlv3.SetBlobEndpoint(blobEndpoint)
+	var feedContainer *blobstorage.Container
+	if feedsContainer != "" {
+		if legacyAtomGenerator || sharedServer {
+			setupLog.Error(errors.New("feeds-container cannot be combined with legacy-atom-generator or shared-server"), "Published feeds are not served by pods.")
+			os.Exit(1)
+		}
+		if azureStorageConnectionString == "" {
+			setupLog.Error(errors.New("azure-storage-connection-string is a required flag"), "A value for azure-storage-connection-string must be specified to publish feeds.")
+			os.Exit(1)
+		}
+		var err error
+		if feedContainer, err = blobstorage.NewContainer(azureStorageConnectionString, feedsContainer); err != nil {
+			setupLog.Error(err, "unable to create blob storage client")
+			os.Exit(1)
+		}
+	}
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -155,8 +223,15 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
+	cacheByObject, err := controller.GetCacheByObject()
+	if err != nil {
+		setupLog.Error(err, "unable to restrict the cache")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cache.Options{ByObject: cacheByObject},
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
@@ -183,20 +258,68 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The cache does not run yet, so the AtomOperatorConfig is read from the API server to check the required settings
+	ctx := ctrl.SetupSignalHandler()
+	startupSettings := *settings
+	startupSettings.Reader = mgr.GetAPIReader()
+	requiredSettings, err := startupSettings.GetSettings(ctx, "")
+	if err != nil {
+		setupLog.Error(err, "unable to load operator config")
+		os.Exit(1)
+	}
+	settings.Reader = mgr.GetClient()
+
+	reqFlags := make(map[string]string)
+	reqFlags["baseurl"] = requiredSettings.BaseURL
+	reqFlags["blobEndpoint"] = requiredSettings.BlobEndpoint
+	reqFlags["lighttpd-image"] = cmp.Or(requiredSettings.LighttpdImage, lighttpdImage)
+	if legacyAtomGenerator {
+		reqFlags["atom-generator-image"] = cmp.Or(requiredSettings.AtomGeneratorImage, atomGeneratorImage)
+	}
+
+	for reqFlag, val := range reqFlags {
+		if val == "" {
+			setupLog.Error(errors.New(reqFlag+" is a required flag"), "A value for "+reqFlag+" must be specified, by the flag or the operator config.")
+			os.Exit(1)
+		}
+	}
+
+	var imageRollout *controller.ImageRollout
+	if maxConcurrentImageRollouts > 0 {
+		imageRollout = controller.NewImageRollout(maxConcurrentImageRollouts)
+	}
+	operatorConfigEvents := make(chan event.GenericEvent, 1024)
 	if err = (&controller.AtomReconciler{
-		Client:             mgr.GetClient(),
-		Scheme:             mgr.GetScheme(),
-		AtomGeneratorImage: atomGeneratorImage,
-		LighttpdImage:      lighttpdImage,
-		CSP:                csp,
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		AtomGeneratorImage:   atomGeneratorImage,
+		LighttpdImage:        lighttpdImage,
+		CSP:                  csp,
+		Settings:             settings,
+		LegacyAtomGenerator:  legacyAtomGenerator,
+		SharedServer:         sharedServer,
+		FeedContainer:        feedContainer,
+		RefreshInterval:      refreshInterval,
+		LinkData:             generator.NewLinkDataCache(),
+		ImageRollout:         imageRollout,
+		Recorder:             mgr.GetEventRecorderFor("atom-operator"),
+		OperatorConfigEvents: operatorConfigEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Atom")
 		os.Exit(1)
 	}
+	if err = (&controller.AtomOperatorConfigReconciler{
+		Client:     mgr.GetClient(),
+		Settings:   settings,
+		AtomEvents: operatorConfigEvents,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AtomOperatorConfig")
+		os.Exit(1)
+	}
 
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 
-		if err = webhookpdoknlv3.SetupAtomWebhookWithManager(mgr); err != nil {
+		if err = webhookpdoknlv3.SetupAtomWebhookWithManager(mgr, settings, legacyAtomGenerator, sharedServer, feedContainer != nil); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Atom")
 			os.Exit(1)
 		}
@@ -215,8 +338,35 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// newSlackCore returns a core that sends errors to the Slack webhook URL of the settings at the time of writing
+func newSlackCore(operatorName string, settings *pdoknlv3.SettingsReader) zapcore.Core {
+	encoderConfig := uberzap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return zapcore.NewCore(
+		zapcore.NewConsoleEncoder(encoderConfig),
+		zapcore.Lock(zapcore.AddSync(slackWriter{operatorName: operatorName, settings: settings})),
+		zapcore.ErrorLevel,
+	)
+}
+
+type slackWriter struct {
+	operatorName string
+	settings     *pdoknlv3.SettingsReader
+}
+
+func (w slackWriter) Write(p []byte) (n int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), slackSettingsTimeout)
+	defer cancel()
+	// Errors are still sent when the AtomOperatorConfig cannot be read, for example before the cache runs
+	slackWebhookURL, err := w.settings.GetSlackWebhookURL(ctx)
+	if err != nil {
+		slackWebhookURL = w.settings.SlackWebhookURL
+	}
+	return (&slack.ZapWriter{OperatorName: w.operatorName, SlackWebhookURL: slackWebhookURL}).Write(p)
+}
@@ -28,6 +28,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"maps"
+	"net/http"
 	"strings"
 	"time"
 
@@ -40,6 +42,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	"github.com/pdok/atom-operator/internal/controller/blobstorage"
+	"github.com/pdok/atom-operator/internal/controller/generator"
 	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
 	smoothoperatorstatus "github.com/pdok/smooth-operator/pkg/status"
 	smoothutil "github.com/pdok/smooth-operator/pkg/util"
@@ -49,9 +53,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -65,8 +73,15 @@ const (
 	downloadsSuffix   = "-atom-downloads-"
 	nameSuffix        = "-atom"
 	generatorSuffix   = "-atom-generator"
+	addPrefixSuffix   = "-atom-addprefix"
+	feedsSuffix       = "-atom-feeds"
+	indexSuffix       = "-atom-index-"
+	sharedServerName  = "atom-shared-server"
 
 	srvDir = "/srv"
+
+	// ownerInfoRefIndexKey indexes Atoms by their spec.service.ownerInfoRef
+	ownerInfoRefIndexKey = "spec.service.ownerInfoRef"
 )
 
 // AtomReconciler reconciles a Atom object
@@ -76,6 +91,26 @@ type AtomReconciler struct {
 	AtomGeneratorImage string
 	LighttpdImage      string
 	CSP                string
+	// Settings reads the settings of the operator for a namespace, the images and CSP above are their defaults
+	Settings *pdoknlv3.SettingsReader
+	// LegacyAtomGenerator generates the feeds in an atom-generator init container instead of in the operator
+	LegacyAtomGenerator bool
+	// SharedServer serves the feeds of all Atoms in a namespace with one shared Deployment instead of a Deployment per Atom
+	SharedServer bool
+	// FeedContainer publishes the feeds to blob storage instead of serving them from a Deployment, when set
+	FeedContainer *blobstorage.Container
+	// HTTPClient is used to request the type and length of downloads, a client with a timeout is used when nil
+	HTTPClient *http.Client
+	// LinkData remembers the type and length of downloads until the feeds are refreshed or the Atom is deleted,
+	// they are requested every reconcile when nil
+	LinkData *generator.LinkDataCache
+	// RefreshInterval is the default interval between refreshes of the feeds, refreshing is disabled when zero
+	RefreshInterval time.Duration
+	// ImageRollout throttles the rollout of new images of the operator over the Atoms, all Atoms get them at once when nil
+	ImageRollout *ImageRollout
+	Recorder     record.EventRecorder
+	// OperatorConfigEvents receives the Atoms whose settings changed with the AtomOperatorConfig
+	OperatorConfigEvents <-chan event.GenericEvent
 }
 
 // +kubebuilder:rbac:groups=pdok.nl,resources=atoms,verbs=get;list;watch;create;update;patch;delete
@@ -85,6 +120,8 @@ type AtomReconciler struct {
 // +kubebuilder:rbac:groups=pdok.nl,resources=ownerinfo/status,verbs=get
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=core,resources=configmaps;services,verbs=watch;create;get;update;list;delete
 // +kubebuilder:rbac:groups=traefik.io,resources=ingressroutes;middlewares,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=create;update;delete;list;watch
@@ -110,12 +147,23 @@ func (r *AtomReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 	if err = r.Get(ctx, req.NamespacedName, atom); err != nil {
 		if apierrors.IsNotFound(err) {
 			lgr.Info("Atom resource not found", "name", req.NamespacedName)
+			r.LinkData.Forget(req.String())
+			if r.SharedServer {
+				// Stop serving the feeds of the deleted Atom
+				_, err = r.createOrUpdateSharedServer(ctx, req.Namespace)
+				return result, err
+			}
 		} else {
 			lgr.Error(err, "unable to fetch Atom resource", "error", err)
 		}
 		return result, client.IgnoreNotFound(err)
 	}
 
+	deleting, err := r.finalizePublishedFeeds(ctx, atom)
+	if deleting || err != nil {
+		return result, err
+	}
+
 	lgr.Info("Fetching OwnerInfo", "name", req.NamespacedName)
 	// Fetch the OwnerInfo instance
 	ownerInfo := &smoothoperatorv1.OwnerInfo{}
@@ -147,74 +195,115 @@ func (r *AtomReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 		return result, err
 	}
 
+	refreshing := false
+	if refreshInterval := r.getRefreshInterval(atom); refreshInterval > 0 {
+		refreshing, result.RequeueAfter = getNextRefresh(atom, refreshInterval, time.Now())
+	}
+	if refreshing {
+		r.LinkData.Forget(generator.GetLinkDataKey(*atom))
+	}
+
+	settings, err := r.getSettings(ctx, atom.Namespace)
+	if err != nil {
+		return result, err
+	}
+
 	lgr.Info("creating resources for atom", "atom", atom)
-	operationResults, err := r.createOrUpdateAllForAtom(ctx, atom, ownerInfo)
+	operationResults, feedsChanged, err := r.createOrUpdateAllForAtom(ctx, atom, ownerInfo, settings)
 	if err != nil {
 		lgr.Info("failed creating resources for atom", "atom", atom)
 		smoothoperatorstatus.LogAndUpdateStatusError(ctx, r.Client, atom, err)
+		if refreshing {
+			r.updateRefreshStatus(ctx, atom, feedsChanged, err)
+		}
 		return result, err
 	}
 	lgr.Info("finished creating resources for atom", "atom", atom)
 	smoothoperatorstatus.LogAndUpdateStatusFinished(ctx, r.Client, atom, operationResults)
+	if refreshing {
+		r.updateRefreshStatus(ctx, atom, feedsChanged, nil)
+	}
+	if progressing := r.updateRolloutStatus(ctx, atom); progressing && (result.RequeueAfter == 0 || result.RequeueAfter > rolloutRequeueAfter) {
+		result.RequeueAfter = rolloutRequeueAfter
+	}
+	if r.ImageRollout != nil {
+		// Check again whether it is the turn of the Deployment of this Atom to roll out the new images
+		deploymentKey := client.ObjectKeyFromObject(r.getBareServerDeployment(atom))
+		if requeueAfter, waiting := r.ImageRollout.getWaitingRequeueAfter(deploymentKey); waiting && (result.RequeueAfter == 0 || result.RequeueAfter > requeueAfter) {
+			result.RequeueAfter = requeueAfter
+		}
+	}
 
 	return result, err
 }
 
-func (r *AtomReconciler) createOrUpdateAllForAtom(ctx context.Context, atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo) (operationResults map[string]controllerutil.OperationResult, err error) {
+// createOrUpdateAllForAtom creates or updates all resources of the Atom, feedsChanged reports whether the rendered feeds changed
+func (r *AtomReconciler) createOrUpdateAllForAtom(ctx context.Context, atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, settings pdoknlv3.OperatorSettings) (operationResults map[string]controllerutil.OperationResult, feedsChanged bool, err error) {
 	operationResults = make(map[string]controllerutil.OperationResult)
 	c := r.Client
 
-	// region Create or update ConfigMap
-	configMap := getBareConfigMap(atom)
-
-	// mutate (also) before to get the hash suffix in the name
-	if err = r.mutateAtomGeneratorConfigMap(atom, ownerInfo, configMap); err != nil {
-		return operationResults, err
-	}
-	operationResults[smoothutil.GetObjectFullName(r.Client, atom)], err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
-		return r.mutateAtomGeneratorConfigMap(atom, ownerInfo, configMap)
-	})
-	if err != nil {
-		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, configMap), err)
-	}
-	// endregion
-
-	// region Create or update Deployment
-	deployment := getBareDeployment(atom)
-	operationResults[smoothutil.GetObjectFullName(r.Client, deployment)], err = controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
-		return r.mutateDeployment(atom, deployment, configMap.GetName())
-	})
-	if err != nil && !strings.Contains(err.Error(), "the object has been modified; please apply your changes to the latest version and try again") {
-		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, deployment), err)
-	}
-	// endregion
-
-	// region Create or update Service
-	service := getBareService(atom)
-	operationResults[smoothutil.GetObjectFullName(r.Client, service)], err = controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
-		return r.mutateService(atom, service)
-	})
-	if err != nil {
-		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, service), err)
+	if r.FeedContainer != nil {
+		// region Publish feeds
+		if feedsChanged, err = r.publishFeeds(ctx, atom, ownerInfo, settings); err != nil {
+			return operationResults, feedsChanged, err
+		}
+		if err = r.deletePerAtomServer(ctx, atom); err != nil {
+			return operationResults, feedsChanged, err
+		}
+		// endregion
+	} else if feedsChanged, err = r.createOrUpdateServer(ctx, atom, ownerInfo, settings, operationResults); err != nil {
+		return operationResults, feedsChanged, err
 	}
-	// endregion
 
 	// region Create or update Middleware
 
-	stripPrefixMiddleware := getBareStripPrefixMiddleware(atom)
-	operationResults[smoothutil.GetObjectFullName(r.Client, stripPrefixMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, stripPrefixMiddleware, func() error {
-		return r.mutateStripPrefixMiddleware(atom, stripPrefixMiddleware)
-	})
-	if err != nil {
-		return operationResults, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, stripPrefixMiddleware), err)
+	if r.FeedContainer != nil {
+		feedsMiddleware := getBareFeedsMiddleware(atom)
+		operationResults[smoothutil.GetObjectFullName(r.Client, feedsMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, feedsMiddleware, func() error {
+			return r.mutateFeedsMiddleware(atom, feedsMiddleware)
+		})
+		if err != nil {
+			return operationResults, feedsChanged, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, feedsMiddleware), err)
+		}
+	} else {
+		stripPrefixMiddleware := getBareStripPrefixMiddleware(atom)
+		operationResults[smoothutil.GetObjectFullName(r.Client, stripPrefixMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, stripPrefixMiddleware, func() error {
+			return r.mutateStripPrefixMiddleware(atom, stripPrefixMiddleware)
+		})
+		if err != nil {
+			return operationResults, feedsChanged, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, stripPrefixMiddleware), err)
+		}
 	}
 
 	corsHeadersMiddleware := getBareHeadersMiddleware(atom)
 	operationResults[smoothutil.GetObjectFullName(r.Client, corsHeadersMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, corsHeadersMiddleware, func() error {
-		return r.mutateHeadersMiddleware(atom, corsHeadersMiddleware, r.CSP)
+		return r.mutateHeadersMiddleware(atom, corsHeadersMiddleware, settings.CSP)
 	})
 	if err != nil {
-		return operationResults, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, corsHeadersMiddleware), err)
+		return operationResults, feedsChanged, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, corsHeadersMiddleware), err)
+	}
+
+	if r.SharedServer {
+		addPrefixMiddleware := getBareAddPrefixMiddleware(atom)
+		operationResults[smoothutil.GetObjectFullName(r.Client, addPrefixMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, addPrefixMiddleware, func() error {
+			return r.mutateAddPrefixMiddleware(atom, addPrefixMiddleware)
+		})
+		if err != nil {
+			return operationResults, feedsChanged, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, addPrefixMiddleware), err)
+		}
+	}
+
+	if !r.LegacyAtomGenerator {
+		// The base URL serves the HTML page of the service feed to browsers and the feed itself to other clients
+		for format, fileName := range map[string]string{"html": "index.html", "xml": "index.xml"} {
+			indexMiddleware := getBareIndexMiddleware(atom, format)
+			operationResults[smoothutil.GetObjectFullName(r.Client, indexMiddleware)], err = controllerutil.CreateOrUpdate(ctx, r.Client, indexMiddleware, func() error {
+				return r.mutateIndexMiddleware(atom, fileName, indexMiddleware)
+			})
+			if err != nil {
+				return operationResults, feedsChanged, fmt.Errorf("could not create or update resource %s: %w", smoothutil.GetObjectFullName(c, indexMiddleware), err)
+			}
+		}
 	}
 
 	// Create or update extra middleware per downloadLink
@@ -224,7 +313,7 @@ func (r *AtomReconciler) createOrUpdateAllForAtom(ctx context.Context, atom *pdo
 			return r.mutateDownloadLinkMiddleware(atom, prefix, group.files, downloadLinkMiddleware)
 		})
 		if err != nil {
-			return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, downloadLinkMiddleware), err)
+			return operationResults, feedsChanged, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, downloadLinkMiddleware), err)
 		}
 	}
 
@@ -236,27 +325,119 @@ func (r *AtomReconciler) createOrUpdateAllForAtom(ctx context.Context, atom *pdo
 		return r.mutateIngressRoute(atom, ingressRoute)
 	})
 	if err != nil {
-		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, ingressRoute), err)
+		return operationResults, feedsChanged, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, ingressRoute), err)
 	}
 
 	// endregion
 
+	// The IngressRoute no longer points to the shared server, so this Atom can release it
+	if !r.SharedServer {
+		if err = r.releaseSharedServer(ctx, atom); err != nil {
+			return operationResults, feedsChanged, err
+		}
+	}
+
+	return operationResults, feedsChanged, nil
+}
+
+// createOrUpdateServer creates the ConfigMap with the feeds and the server that serves them.
+// feedsChanged reports whether the ConfigMap was created or updated, because the rendered feeds differ from the current ConfigMap.
+func (r *AtomReconciler) createOrUpdateServer(ctx context.Context, atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, settings pdoknlv3.OperatorSettings, operationResults map[string]controllerutil.OperationResult) (feedsChanged bool, err error) {
+	c := r.Client
+
+	// region Create or update ConfigMap
+	configMap := getBareConfigMap(atom)
+
+	// mutate (also) before to get the hash suffix in the name, the shared server uses a fixed name
+	if !r.SharedServer {
+		if err = r.mutateAtomGeneratorConfigMap(atom, ownerInfo, settings, configMap); err != nil {
+			return feedsChanged, err
+		}
+	}
+	var configMapResult controllerutil.OperationResult
+	configMapResult, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		return r.mutateAtomGeneratorConfigMap(atom, ownerInfo, settings, configMap)
+	})
+	operationResults[smoothutil.GetObjectFullName(r.Client, atom)] = configMapResult
+	// The ConfigMap of a single Atom is immutable and named after its contents, so changed feeds result in a new ConfigMap
+	feedsChanged = configMapResult == controllerutil.OperationResultCreated ||
+		(r.SharedServer && configMapResult == controllerutil.OperationResultUpdated)
+	if err != nil {
+		return feedsChanged, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, configMap), err)
+	}
+	// endregion
+
+	// region Create or update the server
+	if r.SharedServer {
+		if err = r.deletePerAtomServer(ctx, atom); err != nil {
+			return feedsChanged, err
+		}
+		var sharedServerResults map[string]controllerutil.OperationResult
+		sharedServerResults, err = r.createOrUpdateSharedServer(ctx, atom.Namespace)
+		maps.Copy(operationResults, sharedServerResults)
+		if err != nil {
+			return feedsChanged, err
+		}
+	} else if err = r.createOrUpdatePerAtomServer(ctx, atom, settings, configMap.GetName(), operationResults); err != nil {
+		return feedsChanged, err
+	}
+	// endregion
+
+	return feedsChanged, nil
+}
+
+// createOrUpdatePerAtomServer creates the Deployment, Service and PodDisruptionBudget that serve the feeds of only this Atom
+func (r *AtomReconciler) createOrUpdatePerAtomServer(ctx context.Context, atom *pdoknlv3.Atom, settings pdoknlv3.OperatorSettings, configMapName string, operationResults map[string]controllerutil.OperationResult) (err error) {
+	c := r.Client
+
+	// region Create or update Deployment
+	deployment := getBareDeployment(atom)
+	operationResults[smoothutil.GetObjectFullName(r.Client, deployment)], err = controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
+		currentImages := getContainerImages(&deployment.Spec.Template.Spec)
+		if err := r.mutateDeployment(atom, settings, deployment, configMapName); err != nil {
+			return err
+		}
+		if r.ImageRollout == nil {
+			return nil
+		}
+		return r.throttleImageRollout(ctx, atom, currentImages, deployment)
+	})
+	if err != nil && !strings.Contains(err.Error(), "the object has been modified; please apply your changes to the latest version and try again") {
+		return fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, deployment), err)
+	}
+	// endregion
+
+	// region Create or update Service
+	service := getBareService(atom)
+	operationResults[smoothutil.GetObjectFullName(r.Client, service)], err = controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		return r.mutateService(atom, service)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, service), err)
+	}
+	// endregion
+
 	// region Create or update PodDisruptionBudget
 	podDisruptionBudget := getBarePodDisruptionBudget(atom)
 	operationResults[smoothutil.GetObjectFullName(r.Client, podDisruptionBudget)], err = controllerutil.CreateOrUpdate(ctx, r.Client, podDisruptionBudget, func() error {
 		return r.mutatePodDisruptionBudget(atom, podDisruptionBudget)
 	})
 	if err != nil {
-		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, podDisruptionBudget), err)
+		return fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, podDisruptionBudget), err)
 	}
 	// endregion
 
-	return operationResults, nil
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AtomReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	// Index the OwnerInfo references of Atoms, so the Atoms of a changed OwnerInfo can be looked up
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &pdoknlv3.Atom{}, ownerInfoRefIndexKey, indexOwnerInfoRef); err != nil {
+		return err
+	}
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&pdoknlv3.Atom{}).
 		Owns(&corev1.ConfigMap{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
@@ -264,9 +445,14 @@ func (r *AtomReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&traefikiov1alpha1.Middleware{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&traefikiov1alpha1.IngressRoute{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&policyv1.PodDisruptionBudget{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Owns(&smoothoperatorv1.OwnerInfo{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Watches(&appsv1.ReplicaSet{}, smoothoperatorstatus.GetReplicaSetEventHandlerForObj(mgr, "Atom")).
-		Complete(r)
+		// The custom metadata link templates are an annotation of the OwnerInfo
+		Watches(&smoothoperatorv1.OwnerInfo{}, handler.EnqueueRequestsFromMapFunc(r.getAtomRequestsForOwnerInfo),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&appsv1.ReplicaSet{}, smoothoperatorstatus.GetReplicaSetEventHandlerForObj(mgr, "Atom"))
+	if r.OperatorConfigEvents != nil {
+		controllerBuilder = controllerBuilder.WatchesRawSource(source.Channel(r.OperatorConfigEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return controllerBuilder.Complete(r)
 }
 
 var defaultLabels = map[string]string{appLabelKey: appName}
@@ -307,3 +493,28 @@ func recoveredPanicToError(rec any) (err error) {
 
 	return
 }
+
+// indexOwnerInfoRef is the index function for ownerInfoRefIndexKey
+func indexOwnerInfoRef(obj client.Object) []string {
+	atom, ok := obj.(*pdoknlv3.Atom)
+	if !ok || atom.Spec.Service.OwnerInfoRef == "" {
+		return nil
+	}
+	return []string{atom.Spec.Service.OwnerInfoRef}
+}
+
+// getAtomRequestsForOwnerInfo returns the requests for the Atoms that refer to the OwnerInfo
+func (r *AtomReconciler) getAtomRequestsForOwnerInfo(ctx context.Context, ownerInfo client.Object) []ctrl.Request {
+	atoms := &pdoknlv3.AtomList{}
+	err := r.List(ctx, atoms, client.InNamespace(ownerInfo.GetNamespace()), client.MatchingFields{ownerInfoRefIndexKey: ownerInfo.GetName()})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list the Atoms of OwnerInfo", "name", ownerInfo.GetName())
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(atoms.Items))
+	for _, atom := range atoms.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&atom)})
+	}
+	return requests
+}
@@ -27,14 +27,19 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/pdok/smooth-operator/model"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pdok/atom-generator/feeds"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
 	atomyaml "sigs.k8s.io/yaml/goyaml.v3"
@@ -47,21 +52,46 @@ import (
 	smoothoperatorutils "github.com/pdok/smooth-operator/pkg/util"
 	smoothoperatorvalidation "github.com/pdok/smooth-operator/pkg/validation"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	traefikiov1alpha1 "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	"github.com/pdok/atom-operator/internal/controller/blobstorage"
 )
 
 const (
-	testImageName1 = "test.test/image:test1"
-	testImageName2 = "test.test/image:test2"
+	testImageName1   = "test.test/image:test1"
+	testImageName2   = "test.test/image:test2"
+	testBlobEndpoint = "http://localazurite.blob.azurite"
 )
 
+// testSettings are the settings of the operator in the tests, without an AtomOperatorConfig
+var testSettings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{BlobEndpoint: testBlobEndpoint}}
+
+// testHTTPClient answers the requests for the type and length of downloads, so no blob storage is needed
+var testHTTPClient = &http.Client{Transport: downloadRoundTripper{}}
+
+type downloadRoundTripper struct{}
+
+func (downloadRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Length": {"2048"}, "Content-Type": {"application/octet-stream"}},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
 var _ = Describe("Testing Atom Controller", func() {
 
 	Context("Testing Mutate functions for Minimal Atom", func() {
@@ -74,6 +104,14 @@ var _ = Describe("Testing Atom Controller", func() {
 
 	})
 
+	Context("Testing Mutate functions for a shared server", func() {
+		testSharedServerMutates("maximum")
+	})
+
+	Context("Testing Mutate functions for published feeds", func() {
+		testPublishedFeedsMutates("maximum")
+	})
+
 	Context("When reconciling a resource", func() {
 
 		ctx := context.Background()
@@ -142,6 +180,7 @@ var _ = Describe("Testing Atom Controller", func() {
 				Scheme:             k8sClient.Scheme(),
 				AtomGeneratorImage: testImageName1,
 				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
 			}
 
 			By("Reconciling the Atom and checking the deployment manifest")
@@ -168,6 +207,7 @@ var _ = Describe("Testing Atom Controller", func() {
 				Scheme:             k8sClient.Scheme(),
 				AtomGeneratorImage: testImageName1,
 				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
 			}
 
 			By("Getting the original Deployment")
@@ -204,12 +244,100 @@ var _ = Describe("Testing Atom Controller", func() {
 			}, "10s", "1s").Should(BeTrue())
 		})
 
+		It("Should record a periodic refresh in the status", func() {
+			controllerReconciler := &AtomReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				AtomGeneratorImage: testImageName1,
+				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
+				RefreshInterval:    time.Hour,
+			}
+
+			By("Marking the Deployment as rolled out, the test cluster does not run pods")
+			deployment := getBareDeployment(clusterAtom)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			replicas := getReplicas(deployment)
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           replicas,
+				UpdatedReplicas:    replicas,
+				ReadyReplicas:      replicas,
+				AvailableReplicas:  replicas,
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+				deployment.Status = appsv1.DeploymentStatus{}
+				Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			})
+
+			By("Reconciling the Atom with refreshing enabled")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: objectKeyAtom})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+			By("Verifying that the unchanged feeds are recorded")
+			atom := &pdoknlv3.Atom{}
+			Expect(k8sClient.Get(ctx, objectKeyAtom, atom)).To(Succeed())
+			Expect(atom.Status.Refresh).NotTo(BeNil())
+			Expect(atom.Status.Refresh.Result).To(Equal(pdoknlv3.RefreshResultUnchanged))
+			lastRefreshTime := atom.Status.Refresh.LastRefreshTime
+
+			By("Reconciling the Atom again before the next refresh is due")
+			result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: objectKeyAtom})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("<", time.Hour))
+			Expect(k8sClient.Get(ctx, objectKeyAtom, atom)).To(Succeed())
+			Expect(atom.Status.Refresh.LastRefreshTime).To(Equal(lastRefreshTime))
+		})
+
+		It("Should check an unfinished rollout before the next refresh", func() {
+			controllerReconciler := &AtomReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				AtomGeneratorImage: testImageName1,
+				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
+				RefreshInterval:    time.Hour,
+			}
+
+			By("Reconciling the Atom with refreshing enabled")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: objectKeyAtom})
+			Expect(err).NotTo(HaveOccurred())
+			// The pods do not start in the test cluster, so the rollout is not finished
+			Expect(result.RequeueAfter).To(Equal(rolloutRequeueAfter))
+		})
+
+		It("Should report the rollout of the Deployment in the status", func() {
+			controllerReconciler := &AtomReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				AtomGeneratorImage: testImageName1,
+				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
+			}
+
+			By("Reconciling the Atom")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: objectKeyAtom})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(rolloutRequeueAfter))
+
+			By("Verifying that the pods that are not ready yet are reported as progressing")
+			atom := &pdoknlv3.Atom{}
+			Expect(k8sClient.Get(ctx, objectKeyAtom, atom)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(atom.Status.Conditions, progressingConditionType)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(atom.Status.Conditions, availableConditionType)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(atom.Status.Conditions, degradedConditionType)).To(BeTrue())
+		})
+
 		It("should maintain labels added externally after a reconcile", func() {
 			controllerReconciler := &AtomReconciler{
 				Client:             k8sClient,
 				Scheme:             k8sClient.Scheme(),
 				AtomGeneratorImage: testImageName1,
 				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
 			}
 
 			By("Getting the original Deployment")
@@ -253,6 +381,7 @@ var _ = Describe("Testing Atom Controller", func() {
 				Scheme:             k8sClient.Scheme(),
 				AtomGeneratorImage: testImageName1,
 				LighttpdImage:      testImageName2,
+				HTTPClient:         testHTTPClient,
 			}
 
 			ttlName := testAtom.GetName() + "-ttl"
@@ -327,9 +456,8 @@ var _ = Describe("Testing Atom Controller", func() {
 
 func testAtomMutates(name string) {
 
-	pdoknlv3.SetBlobEndpoint("http://localazurite.blob.azurite")
-
 	var reconciler AtomReconciler
+	var settings pdoknlv3.OperatorSettings
 
 	inputPath := testPath(name) + "input/"
 	outputPath := testPath(name) + "expected-output/"
@@ -343,7 +471,10 @@ func testAtomMutates(name string) {
 			Scheme:             k8sClient.Scheme(),
 			AtomGeneratorImage: testImageName1,
 			LighttpdImage:      testImageName2,
+			Settings:           testSettings,
+			HTTPClient:         testHTTPClient,
 		}
+		settings = must(reconciler.getSettings(context.Background(), ""))
 	})
 
 	It("Should parse the input files correctly", func() {
@@ -362,13 +493,20 @@ func testAtomMutates(name string) {
 	})
 
 	It("Should generate a correct Configmap", func() {
+		testMutate("ConfigMap", getBareConfigMap(&atom), outputPath+"configmap.yaml", func(c *corev1.ConfigMap) error {
+			return reconciler.mutateAtomGeneratorConfigMap(&atom, &owner, settings, c)
+		})
+	})
+
+	It("Should generate a correct legacy Configmap", func() {
+		reconciler.LegacyAtomGenerator = true
 
 		result := getBareConfigMap(&atom)
-		err := reconciler.mutateAtomGeneratorConfigMap(&atom, &owner, result)
+		err := reconciler.mutateAtomGeneratorConfigMap(&atom, &owner, settings, result)
 		Expect(err).NotTo(HaveOccurred())
 
 		var expected corev1.ConfigMap
-		data, err := os.ReadFile(outputPath + "configmap.yaml")
+		data, err := os.ReadFile(outputPath + "configmap-legacy.yaml")
 		Expect(err).NotTo(HaveOccurred())
 		err = yaml.UnmarshalStrict(data, &expected)
 		Expect(err).NotTo(HaveOccurred())
@@ -396,7 +534,14 @@ func testAtomMutates(name string) {
 
 	It("Should generate a Deployment correctly", func() {
 		testMutate("Deployment", getBareDeployment(&atom), outputPath+"deployment.yaml", func(d *appsv1.Deployment) error {
-			return reconciler.mutateDeployment(&atom, d, name+"-atom-generator")
+			return reconciler.mutateDeployment(&atom, settings, d, name+"-atom-generator")
+		})
+	})
+
+	It("Should generate a legacy Deployment correctly", func() {
+		reconciler.LegacyAtomGenerator = true
+		testMutate("Deployment", getBareDeployment(&atom), outputPath+"deployment-legacy.yaml", func(d *appsv1.Deployment) error {
+			return reconciler.mutateDeployment(&atom, settings, d, name+"-atom-generator")
 		})
 	})
 
@@ -406,6 +551,14 @@ func testAtomMutates(name string) {
 		})
 	})
 
+	It("Should generate correct Index Middlewares", func() {
+		for _, format := range []string{"html", "xml"} {
+			testMutate("Index Middleware", getBareIndexMiddleware(&atom, format), outputPath+"middleware-index-"+format+".yaml", func(m *traefikiov1alpha1.Middleware) error {
+				return reconciler.mutateIndexMiddleware(&atom, "index."+format, m)
+			})
+		}
+	})
+
 	It("Should generate a correct Prefix Strip Middleware", func() {
 		testMutate("Prefix Strip Middleware", getBareStripPrefixMiddleware(&atom), outputPath+"middleware-prefixstrip.yaml", func(m *traefikiov1alpha1.Middleware) error {
 			return reconciler.mutateStripPrefixMiddleware(&atom, m)
@@ -441,6 +594,109 @@ func testAtomMutates(name string) {
 
 }
 
+func testSharedServerMutates(name string) {
+	var reconciler AtomReconciler
+	var settings pdoknlv3.OperatorSettings
+
+	inputPath := testPath(name) + "input/"
+	outputPath := testPath(name) + "expected-output/shared-server/"
+
+	atom := pdoknlv3.Atom{}
+	owner := smoothoperatorv1.OwnerInfo{}
+	configMap := corev1.ConfigMap{}
+
+	BeforeEach(func() {
+		reconciler = AtomReconciler{
+			Client:        k8sClient,
+			Scheme:        k8sClient.Scheme(),
+			LighttpdImage: testImageName2,
+			Settings:      testSettings,
+			SharedServer:  true,
+			HTTPClient:    testHTTPClient,
+		}
+		settings = must(reconciler.getSettings(context.Background(), ""))
+	})
+
+	It("Should parse the input files correctly", func() {
+		atom = *must(getAtom(inputPath+"atom.yaml", true))
+		owner = *must(getOwnerInfo(inputPath+"ownerinfo.yaml", true))
+	})
+
+	It("Should generate a correct Configmap without hash suffix", func() {
+		testMutate("ConfigMap", getBareConfigMap(&atom), outputPath+"configmap.yaml", func(c *corev1.ConfigMap) error {
+			return reconciler.mutateAtomGeneratorConfigMap(&atom, &owner, settings, c)
+		})
+		configMap = *getBareConfigMap(&atom)
+		Expect(reconciler.mutateAtomGeneratorConfigMap(&atom, &owner, settings, &configMap)).To(Succeed())
+	})
+
+	It("Should generate a correct shared Deployment", func() {
+		testMutate("Deployment", getBareSharedServerDeployment(atom.Namespace), outputPath+"deployment.yaml", func(d *appsv1.Deployment) error {
+			return reconciler.mutateSharedServerDeployment([]pdoknlv3.Atom{atom}, map[string]corev1.ConfigMap{atom.Name: configMap}, settings, d)
+		})
+	})
+
+	It("Should generate a correct shared Service", func() {
+		testMutate("Service", getBareSharedServerService(atom.Namespace), outputPath+"service.yaml", func(s *corev1.Service) error {
+			return reconciler.mutateSharedServerService([]pdoknlv3.Atom{atom}, s)
+		})
+	})
+
+	It("Should generate a correct shared PodDisruptionBudget", func() {
+		testMutate("PodDisruptionBudget", getBareSharedServerPodDisruptionBudget(atom.Namespace), outputPath+"poddisruptionbudget.yaml", func(p *policyv1.PodDisruptionBudget) error {
+			return reconciler.mutateSharedServerPodDisruptionBudget([]pdoknlv3.Atom{atom}, p)
+		})
+	})
+
+	It("Should generate a correct Add Prefix Middleware", func() {
+		testMutate("Add Prefix Middleware", getBareAddPrefixMiddleware(&atom), outputPath+"middleware-addprefix.yaml", func(m *traefikiov1alpha1.Middleware) error {
+			return reconciler.mutateAddPrefixMiddleware(&atom, m)
+		})
+	})
+
+	It("Should generate a correct IngressRoute to the shared server", func() {
+		testMutate("IngressRoute", getBareIngressRoute(&atom), outputPath+"ingressroute.yaml", func(i *traefikiov1alpha1.IngressRoute) error {
+			return reconciler.mutateIngressRoute(&atom, i)
+		})
+	})
+}
+
+func testPublishedFeedsMutates(name string) {
+	var reconciler AtomReconciler
+
+	inputPath := testPath(name) + "input/"
+	outputPath := testPath(name) + "expected-output/published-feeds/"
+
+	atom := pdoknlv3.Atom{}
+
+	BeforeEach(func() {
+		feedContainer, err := blobstorage.NewContainer("DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=a2V5;"+
+			"BlobEndpoint=http://localazurite.blob.azurite/devstoreaccount1;", "atom/feeds")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = AtomReconciler{
+			Client:        k8sClient,
+			Scheme:        k8sClient.Scheme(),
+			FeedContainer: feedContainer,
+		}
+	})
+
+	It("Should parse the input files correctly", func() {
+		atom = *must(getAtom(inputPath+"atom.yaml", true))
+	})
+
+	It("Should generate a correct Feeds Middleware", func() {
+		testMutate("Feeds Middleware", getBareFeedsMiddleware(&atom), outputPath+"middleware-feeds.yaml", func(m *traefikiov1alpha1.Middleware) error {
+			return reconciler.mutateFeedsMiddleware(&atom, m)
+		})
+	})
+
+	It("Should generate a correct IngressRoute to the blob storage", func() {
+		testMutate("IngressRoute", getBareIngressRoute(&atom), outputPath+"ingressroute.yaml", func(i *traefikiov1alpha1.IngressRoute) error {
+			return reconciler.mutateIngressRoute(&atom, i)
+		})
+	})
+}
+
 func testPath(name string) string {
 	return fmt.Sprintf("test_data/%s-atom/", name)
 }
@@ -525,7 +781,6 @@ func getExpectedBareObjectsForAtom(atom *pdoknlv3.Atom, configMapName string) []
 }
 
 func Test_getGeneratorConfig(t *testing.T) {
-	pdoknlv3.SetBlobEndpoint("http://localazurite.blob.azurite")
 	type args struct {
 		atom      *pdoknlv3.Atom
 		ownerInfo *smoothoperatorv1.OwnerInfo
@@ -564,14 +819,14 @@ func Test_getGeneratorConfig(t *testing.T) {
 		{
 			name:       "maximum_scenario",
 			args:       maxScenario,
-			wantConfig: getTestGeneratorConfig(testPath("maximum") + "expected-output/configmap.yaml"),
+			wantConfig: getTestGeneratorConfig(testPath("maximum") + "expected-output/configmap-legacy.yaml"),
 			wantErr:    false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			gotConfig, err := getGeneratorConfig(tt.args.atom, tt.args.ownerInfo)
+			gotConfig, err := getGeneratorConfig(tt.args.atom, tt.args.ownerInfo, testBlobEndpoint)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getGeneratorConfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -582,6 +837,494 @@ func Test_getGeneratorConfig(t *testing.T) {
 	}
 }
 
+func Test_getNextRefresh(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	refreshedAt := func(lastRefresh time.Time) *pdoknlv3.Atom {
+		return &pdoknlv3.Atom{Status: pdoknlv3.AtomStatus{Refresh: &pdoknlv3.RefreshStatus{
+			LastRefreshTime: metav1.NewTime(lastRefresh),
+			Result:          pdoknlv3.RefreshResultUnchanged,
+		}}}
+	}
+
+	tests := []struct {
+		name             string
+		atom             *pdoknlv3.Atom
+		wantDue          bool
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name:             "never_refreshed",
+			atom:             &pdoknlv3.Atom{},
+			wantDue:          true,
+			wantRequeueAfter: time.Hour,
+		},
+		{
+			name:             "refreshed_recently",
+			atom:             refreshedAt(now.Add(-15 * time.Minute)),
+			wantDue:          false,
+			wantRequeueAfter: 45 * time.Minute,
+		},
+		{
+			name:             "refresh_overdue",
+			atom:             refreshedAt(now.Add(-2 * time.Hour)),
+			wantDue:          true,
+			wantRequeueAfter: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDue, gotRequeueAfter := getNextRefresh(tt.atom, time.Hour, now)
+			if gotDue != tt.wantDue || gotRequeueAfter != tt.wantRequeueAfter {
+				t.Errorf("getNextRefresh() = %v, %v, want %v, %v", gotDue, gotRequeueAfter, tt.wantDue, tt.wantRequeueAfter)
+			}
+		})
+	}
+}
+
+func Test_getRolloutConditions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	getDeployment := func(status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "atom", Generation: 2},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: smoothoperatorutils.Pointer(int32(2)),
+			},
+			Status: status,
+		}
+	}
+	crashingPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "atom-new", Labels: map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "new"}},
+		Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+			Name:                 "atom-generator",
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "panic: download not found\n"}},
+		}}},
+	}
+	oldCrashingPod := *crashingPod.DeepCopy()
+	// Image rollouts keep the ConfigMap, only the pod-template-hash tells the pods apart
+	oldCrashingPod.Name = "atom-old"
+	oldCrashingPod.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "old"
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		pods       []corev1.Pod
+		want       map[string]metav1.ConditionStatus
+		wantReason map[string]string
+	}{
+		{
+			name: "rolled_out",
+			deployment: getDeployment(appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+			}),
+			pods:       []corev1.Pod{oldCrashingPod},
+			want:       map[string]metav1.ConditionStatus{progressingConditionType: metav1.ConditionFalse, availableConditionType: metav1.ConditionTrue, degradedConditionType: metav1.ConditionFalse},
+			wantReason: map[string]string{progressingConditionType: "RolloutComplete"},
+		},
+		{
+			name: "rolling_out",
+			deployment: getDeployment(appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 2, AvailableReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+			}),
+			want:       map[string]metav1.ConditionStatus{progressingConditionType: metav1.ConditionTrue, availableConditionType: metav1.ConditionTrue, degradedConditionType: metav1.ConditionFalse},
+			wantReason: map[string]string{progressingConditionType: "RollingOut"},
+		},
+		{
+			name: "init_container_fails",
+			deployment: getDeployment(appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2,
+			}),
+			pods:       []corev1.Pod{crashingPod},
+			want:       map[string]metav1.ConditionStatus{progressingConditionType: metav1.ConditionFalse, availableConditionType: metav1.ConditionFalse, degradedConditionType: metav1.ConditionTrue},
+			wantReason: map[string]string{degradedConditionType: "PodFailing"},
+		},
+		{
+			name: "deadline_exceeded",
+			deployment: getDeployment(appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}},
+			}),
+			want:       map[string]metav1.ConditionStatus{progressingConditionType: metav1.ConditionFalse, availableConditionType: metav1.ConditionFalse, degradedConditionType: metav1.ConditionTrue},
+			wantReason: map[string]string{degradedConditionType: "ProgressDeadlineExceeded"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getRolloutConditions(tt.deployment, "new", tt.pods, 3, now)
+			for conditionType, wantStatus := range tt.want {
+				condition := meta.FindStatusCondition(got, conditionType)
+				if condition == nil || condition.Status != wantStatus || condition.ObservedGeneration != 3 {
+					t.Errorf("getRolloutConditions() %s = %v, want status %s", conditionType, condition, wantStatus)
+				}
+			}
+			for conditionType, wantReason := range tt.wantReason {
+				if condition := meta.FindStatusCondition(got, conditionType); condition == nil || condition.Reason != wantReason {
+					t.Errorf("getRolloutConditions() %s = %v, want reason %s", conditionType, condition, wantReason)
+				}
+			}
+		})
+	}
+
+	degraded := meta.FindStatusCondition(getRolloutConditions(tests[2].deployment, "new", tests[2].pods, 3, now), degradedConditionType)
+	wantMessage := "container atom-generator of pod atom-new failed with exit code 1: panic: download not found"
+	if degraded.Message != wantMessage {
+		t.Errorf("getRolloutConditions() degraded message = %s, want %s", degraded.Message, wantMessage)
+	}
+}
+
+func Test_GetCacheByObject(t *testing.T) {
+	cacheByObject, err := GetCacheByObject()
+	require.NoError(t, err)
+	require.Len(t, cacheByObject, 2)
+	for obj, byObject := range cacheByObject {
+		require.True(t, byObject.Label.Matches(labels.Set{appLabelKey: appName}), "%T of an Atom are cached", obj)
+		require.True(t, byObject.Label.Matches(labels.Set{appLabelKey: sharedServerName}), "%T of the shared server are cached", obj)
+		require.False(t, byObject.Label.Matches(labels.Set{appLabelKey: "other"}), "%T of other apps are not cached", obj)
+		require.False(t, byObject.Label.Matches(labels.Set{}), "%T without app are not cached", obj)
+	}
+}
+
+func Test_getPodTemplateHash(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "atom", UID: "atom-uid", Generation: 2, Annotations: map[string]string{deploymentRevisionAnnotation: "2"},
+	}}
+	getReplicaSet := func(revision, hash string) appsv1.ReplicaSet {
+		replicaSet := appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:        "atom-" + hash,
+			Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+			Labels:      map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash},
+		}}
+		replicaSet.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+		return replicaSet
+	}
+	replicaSets := []appsv1.ReplicaSet{getReplicaSet("1", "old"), getReplicaSet("2", "new")}
+
+	if got := getPodTemplateHash(deployment, replicaSets); got != "" {
+		t.Errorf("getPodTemplateHash() = %s, want none before the Deployment controller observed the pod template", got)
+	}
+	deployment.Status.ObservedGeneration = 2
+	if got := getPodTemplateHash(deployment, replicaSets); got != "new" {
+		t.Errorf("getPodTemplateHash() = %s, want the hash of the newest ReplicaSet", got)
+	}
+	if got := getPodTemplateHash(deployment, replicaSets[:1]); got != "" {
+		t.Errorf("getPodTemplateHash() = %s, want none without the ReplicaSet of the current revision", got)
+	}
+}
+
+func Test_getImages(t *testing.T) {
+	reconciler := AtomReconciler{AtomGeneratorImage: testImageName1, LighttpdImage: testImageName2}
+	settings, err := reconciler.getSettings(context.Background(), "")
+	require.NoError(t, err)
+	overridden := &pdoknlv3.Atom{Spec: pdoknlv3.AtomSpec{Images: &pdoknlv3.Images{Lighttpd: smoothoperatorutils.Pointer("registry.test/lighttpd:canary")}}}
+
+	if got := getLighttpdImage(overridden, settings); got != "registry.test/lighttpd:canary" {
+		t.Errorf("getLighttpdImage() = %s, want the override", got)
+	}
+	if got := getAtomGeneratorImage(overridden, settings); got != testImageName1 {
+		t.Errorf("getAtomGeneratorImage() = %s, want the image of the operator %s", got, testImageName1)
+	}
+
+	pod := func(phase corev1.PodPhase, lighttpdImage string) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "atom-generator", Image: testImageName1}},
+				Containers:     []corev1.Container{{Name: "atom-service", Image: lighttpdImage}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	got := getRunningImages([]corev1.Pod{
+		pod(corev1.PodRunning, testImageName2),
+		pod(corev1.PodPending, "registry.test/lighttpd:canary"),
+		pod(corev1.PodFailed, "registry.test/lighttpd:failed"),
+	})
+	want := []pdoknlv3.ContainerImage{
+		{Container: "atom-generator", Image: testImageName1},
+		{Container: "atom-service", Image: "registry.test/lighttpd:canary"},
+		{Container: "atom-service", Image: testImageName2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("getRunningImages() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_throttleImageRollout(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	newAtom := func(name, priority string) *pdoknlv3.Atom {
+		atom := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{}}}
+		if priority != "" {
+			atom.Labels[rolloutPriorityLabel] = priority
+		}
+		return atom
+	}
+	newDeployment := func(atom *pdoknlv3.Atom, image string, rolledOut bool) *appsv1.Deployment {
+		deployment := getBareDeployment(atom)
+		deployment.Labels = defaultLabels
+		deployment.Spec.Replicas = smoothoperatorutils.Pointer(int32(2))
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "atom-service", Image: image}}
+		if rolledOut {
+			deployment.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+		}
+		return deployment
+	}
+	// newReplicaSet returns a ReplicaSet of the Deployment of the Atom whose pods still run the image
+	newReplicaSet := func(atom *pdoknlv3.Atom, image string) *appsv1.ReplicaSet {
+		deployment := getBareDeployment(atom)
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            deployment.Name + "-" + image,
+				Namespace:       deployment.Namespace,
+				Labels:          defaultLabels,
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, UID: "uid", Controller: smoothoperatorutils.Pointer(true)}},
+			},
+			Spec:   appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "atom-service", Image: image}}}}},
+			Status: appsv1.ReplicaSetStatus{Replicas: 1},
+		}
+	}
+	low, high, none := newAtom("low", "1"), newAtom("high", "5"), newAtom("none", "")
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		low, high, none,
+		newDeployment(low, "old", true), newDeployment(high, "old", true), newDeployment(none, "old", true),
+	).Build()
+	newImageRollout := func(maxConcurrent int) *ImageRollout {
+		imageRollout := NewImageRollout(maxConcurrent)
+		imageRollout.StateMaxAge = 0
+		return imageRollout
+	}
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme, LighttpdImage: "new", ImageRollout: newImageRollout(1)}
+
+	// throttle returns the image the Deployment of the Atom gets
+	throttle := func(atom *pdoknlv3.Atom) string {
+		deployment := getBareDeployment(atom)
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment))
+		currentImages := getContainerImages(&deployment.Spec.Template.Spec)
+		deployment.Spec.Template.Spec.Containers[0].Image = "new"
+		require.NoError(t, reconciler.throttleImageRollout(ctx, atom, currentImages, deployment))
+		return deployment.Spec.Template.Spec.Containers[0].Image
+	}
+	updateDeployment := func(deployment *appsv1.Deployment) {
+		existing := &appsv1.Deployment{}
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), existing))
+		deployment.ResourceVersion = existing.ResourceVersion
+		status := deployment.Status
+		require.NoError(t, fakeClient.Update(ctx, deployment))
+		deployment.Status = status
+		require.NoError(t, fakeClient.Status().Update(ctx, deployment))
+	}
+	deploymentKey := func(atom *pdoknlv3.Atom) types.NamespacedName {
+		return client.ObjectKeyFromObject(getBareDeployment(atom))
+	}
+
+	require.Equal(t, "old", throttle(low), "an Atom with a lower priority has to wait")
+	require.True(t, reconciler.ImageRollout.IsWaiting(deploymentKey(low)))
+	require.Equal(t, "new", throttle(high), "the Atom with the highest priority goes first")
+	require.False(t, reconciler.ImageRollout.IsWaiting(deploymentKey(high)))
+	require.Equal(t, "old", throttle(low), "the turn is remembered until the cache shows the new images")
+
+	updateDeployment(newDeployment(high, "new", false))
+	require.NoError(t, fakeClient.Create(ctx, newReplicaSet(high, "old")))
+	require.Equal(t, "old", throttle(low), "only 1 Atom may roll out at the same time")
+	require.Empty(t, reconciler.ImageRollout.started, "the rollout is followed in the cluster once the cache shows the new images")
+
+	reconciler.ImageRollout = newImageRollout(1)
+	require.Equal(t, "old", throttle(low), "a rollout that started before a restart is counted")
+
+	failing := newDeployment(high, "new", false)
+	failing.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}}
+	updateDeployment(failing)
+	reconciler.ImageRollout.MaxConcurrent = 2
+	require.Equal(t, "old", throttle(low), "the rollout pauses while an Atom fails with the new images")
+
+	updateDeployment(newDeployment(high, "new", true))
+	reconciler.ImageRollout.MaxConcurrent = 1
+	require.Equal(t, "old", throttle(none), "an Atom without priority comes last")
+	require.Equal(t, "new", throttle(low), "the next Atom goes after the rollout finished")
+	requeueAfter, waiting := reconciler.ImageRollout.getWaitingRequeueAfter(deploymentKey(none))
+	require.True(t, waiting)
+	require.Equal(t, rolloutRequeueAfter, requeueAfter)
+	require.Equal(t, "old", throttle(none))
+	requeueAfter, _ = reconciler.ImageRollout.getWaitingRequeueAfter(deploymentKey(none))
+	require.Equal(t, 2*rolloutRequeueAfter, requeueAfter, "an Atom checks less often the longer it waits")
+
+	updateDeployment(newDeployment(low, "new", true))
+	require.Equal(t, "new", throttle(low))
+	require.False(t, reconciler.ImageRollout.started[deploymentKey(low)], "the rollout of an Atom ends when it is rolled out")
+	require.Equal(t, "new", throttle(none), "the last Atom goes after the rollout of the others finished")
+	updateDeployment(newDeployment(none, "new", true))
+	require.Equal(t, "new", throttle(none))
+	require.Empty(t, reconciler.ImageRollout.started)
+	require.Zero(t, testutil.ToFloat64(imageRolloutOutdatedGauge), "the metrics drop to zero after the rollout finished")
+	require.Zero(t, testutil.ToFloat64(imageRolloutInProgressGauge))
+}
+
+func Test_throttleImageRolloutSharedServer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	shared := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "shared", Labels: map[string]string{rolloutPriorityLabel: "5"}}}
+	single := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "single", Namespace: "default", Labels: map[string]string{rolloutPriorityLabel: "1"}}}
+	sharedDeployment := getBareSharedServerDeployment(shared.Namespace)
+	sharedDeployment.Labels = sharedServerLabels
+	singleDeployment := getBareDeployment(single)
+	singleDeployment.Labels = defaultLabels
+	for _, deployment := range []*appsv1.Deployment{sharedDeployment, singleDeployment} {
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "atom-service", Image: "old"}}
+		deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(shared, single, sharedDeployment, singleDeployment).Build()
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme, LighttpdImage: "new", ImageRollout: NewImageRollout(1)}
+	reconciler.ImageRollout.StateMaxAge = 0
+
+	// throttle returns the image the Deployment gets
+	throttle := func(atom *pdoknlv3.Atom, deployment *appsv1.Deployment) string {
+		deployment = deployment.DeepCopy()
+		currentImages := getContainerImages(&deployment.Spec.Template.Spec)
+		deployment.Spec.Template.Spec.Containers[0].Image = "new"
+		require.NoError(t, reconciler.throttleImageRollout(ctx, atom, currentImages, deployment))
+		return deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	require.Equal(t, "old", throttle(single, singleDeployment), "the shared server gets the highest priority of the Atoms in its namespace")
+	require.Equal(t, "new", throttle(nil, sharedDeployment), "the shared server is throttled like the Deployment of an Atom")
+	require.Equal(t, "old", throttle(single, singleDeployment), "only 1 Deployment may roll out at the same time")
+}
+
+func Test_releaseSharedServer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	first := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default", UID: "first-uid"}}
+	second := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "default", UID: "second-uid"}}
+	service := getBareSharedServerService("default")
+	configMap := getBareConfigMap(first)
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(first, second, service, configMap).Build()
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, reconciler.setSharedOwnerReferences([]pdoknlv3.Atom{*first, *second}, service))
+	require.NoError(t, fakeClient.Update(ctx, service))
+
+	require.NoError(t, reconciler.releaseSharedServer(ctx, first))
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(service), service))
+	require.Len(t, service.OwnerReferences, 1)
+	require.Equal(t, second.UID, service.OwnerReferences[0].UID, "the shared server keeps serving the other Atom")
+	err := fakeClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	require.True(t, apierrors.IsNotFound(err), "the ConfigMap of the released Atom is deleted")
+
+	require.NoError(t, reconciler.releaseSharedServer(ctx, second))
+	err = fakeClient.Get(ctx, client.ObjectKeyFromObject(service), service)
+	require.True(t, apierrors.IsNotFound(err), "the shared server is deleted after the last Atom released it")
+
+	require.NoError(t, reconciler.releaseSharedServer(ctx, second), "there is nothing to release without shared server")
+}
+
+func Test_getAtomRequestsForOwnerInfo(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	getAtom := func(name, namespace, ownerInfoRef string) *pdoknlv3.Atom {
+		atom := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		atom.Spec.Service.OwnerInfoRef = ownerInfoRef
+		return atom
+	}
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&pdoknlv3.Atom{}, ownerInfoRefIndexKey, indexOwnerInfoRef).
+		WithObjects(getAtom("first", "default", "pdok"), getAtom("second", "default", "pdok"),
+			getAtom("other-owner", "default", "other"), getAtom("other-namespace", "other", "pdok")).
+		Build()
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme}
+
+	ownerInfo := &smoothoperatorv1.OwnerInfo{ObjectMeta: metav1.ObjectMeta{Name: "pdok", Namespace: "default"}}
+	require.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "first", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "second", Namespace: "default"}},
+	}, reconciler.getAtomRequestsForOwnerInfo(ctx, ownerInfo))
+}
+
+func Test_deletePerAtomServer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	atom := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "atom", Namespace: "default"}}
+	var deleted []string
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(atom, getBareService(atom)).
+		WithInterceptorFuncs(interceptor.Funcs{Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleted = append(deleted, obj.GetName())
+			return c.Delete(ctx, obj, opts...)
+		}}).Build()
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme}
+
+	require.NoError(t, reconciler.deletePerAtomServer(ctx, atom))
+	require.Equal(t, []string{getBareService(atom).Name}, deleted, "only the existing Service is deleted")
+	require.NoError(t, reconciler.deletePerAtomServer(ctx, atom))
+	require.Len(t, deleted, 1, "nothing is deleted when the per-Atom server is gone")
+}
+
+func Test_finalizePublishedFeeds(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	atom := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "atom", Namespace: "default"}}
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(atom).Build()
+	feedContainer, err := blobstorage.NewContainer("DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=a2V5;"+
+		"BlobEndpoint=http://localazurite.blob.azurite/devstoreaccount1;", "atom/feeds")
+	require.NoError(t, err)
+	reconciler := AtomReconciler{Client: fakeClient, Scheme: scheme, FeedContainer: feedContainer}
+
+	deleting, err := reconciler.finalizePublishedFeeds(ctx, atom)
+	require.NoError(t, err)
+	require.False(t, deleting)
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(atom), atom))
+	require.Equal(t, []string{publishedFeedsFinalizer}, atom.Finalizers, "Atoms get a finalizer when their feeds are published")
+
+	// The operator no longer publishes feeds, so the finalizer should not keep the deleted Atom
+	reconciler.FeedContainer = nil
+	require.NoError(t, fakeClient.Delete(ctx, atom))
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(atom), atom))
+	deleting, err = reconciler.finalizePublishedFeeds(ctx, atom)
+	require.NoError(t, err)
+	require.True(t, deleting)
+	err = fakeClient.Get(ctx, client.ObjectKeyFromObject(atom), atom)
+	require.True(t, apierrors.IsNotFound(err), "the Atom is deleted after its finalizer is removed")
+}
+
+func Test_getRefreshInterval(t *testing.T) {
+	withRefresh := &pdoknlv3.Atom{Spec: pdoknlv3.AtomSpec{Refresh: &pdoknlv3.Refresh{Interval: metav1.Duration{Duration: time.Minute}}}}
+
+	tests := []struct {
+		name       string
+		reconciler AtomReconciler
+		atom       *pdoknlv3.Atom
+		want       time.Duration
+	}{
+		{name: "disabled", atom: &pdoknlv3.Atom{}, want: 0},
+		{name: "operator_interval", reconciler: AtomReconciler{RefreshInterval: time.Hour}, atom: &pdoknlv3.Atom{}, want: time.Hour},
+		{name: "atom_overrides_operator", reconciler: AtomReconciler{RefreshInterval: time.Hour}, atom: withRefresh, want: time.Minute},
+		{name: "legacy_never_refreshes", reconciler: AtomReconciler{LegacyAtomGenerator: true}, atom: withRefresh, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.reconciler.getRefreshInterval(tt.atom); got != tt.want {
+				t.Errorf("getRefreshInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func readTestFile(fileName string) (string, error) {
 	dat, err := os.ReadFile(fileName)
 
@@ -22,17 +22,32 @@ func getBareConfigMap(obj metav1.Object) *corev1.ConfigMap {
 	}
 }
 
-func (r *AtomReconciler) mutateAtomGeneratorConfigMap(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, configMap *corev1.ConfigMap) error {
+func (r *AtomReconciler) mutateAtomGeneratorConfigMap(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, settings pdoknlv3.OperatorSettings, configMap *corev1.ConfigMap) error {
 	configMap.Labels = getObjectLabels(atom, configMap.Labels)
 
-	if len(configMap.Data) == 0 {
-		generatorConfig, err := getGeneratorConfig(atom, ownerInfo)
-		if err != nil {
-			return err
+	// The ConfigMap of the shared server keeps its name, so it is updated in place instead of rolling out the shared server
+	if len(configMap.Data) == 0 || r.SharedServer {
+		if r.LegacyAtomGenerator {
+			generatorConfig, err := getGeneratorConfig(atom, ownerInfo, settings.BlobEndpoint)
+			if err != nil {
+				return err
+			}
+			configMap.Data = map[string]string{configFileName: generatorConfig}
+		} else {
+			renderedFeeds, err := r.getRenderedFeeds(atom, ownerInfo, settings.BlobEndpoint)
+			if err != nil {
+				return err
+			}
+			if size := generator.GetRenderedSize(renderedFeeds); size > generator.MaxConfigMapSize {
+				return fmt.Errorf("the rendered feeds are %d bytes, which does not fit in a ConfigMap of at most %d bytes, publish the feeds to blob storage instead",
+					size, generator.MaxConfigMapSize)
+			}
+			configMap.Data = renderedFeeds
 		}
-		configMap.Data = map[string]string{configFileName: generatorConfig}
 	}
-	configMap.Immutable = smoothutil.Pointer(true)
+	if !r.SharedServer {
+		configMap.Immutable = smoothutil.Pointer(true)
+	}
 
 	if err := smoothutil.EnsureSetGVK(r.Client, configMap, configMap); err != nil {
 		return err
@@ -40,11 +55,14 @@ func (r *AtomReconciler) mutateAtomGeneratorConfigMap(atom *pdoknlv3.Atom, owner
 	if err := ctrl.SetControllerReference(atom, configMap, r.Scheme); err != nil {
 		return err
 	}
+	if r.SharedServer {
+		return nil
+	}
 	return smoothutil.AddHashSuffix(configMap)
 }
 
-func getGeneratorConfig(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo) (config string, err error) {
-	atomGeneratorConfig, err := generator.MapAtomV3ToAtomGeneratorConfig(*atom, *ownerInfo)
+func getGeneratorConfig(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, blobEndpoint string) (config string, err error) {
+	atomGeneratorConfig, err := generator.MapAtomV3ToAtomGeneratorConfig(*atom, *ownerInfo, blobEndpoint)
 	if err != nil {
 		return "", fmt.Errorf("failed to map the V3 atom to generator config: %w", err)
 	}
@@ -55,3 +73,18 @@ func getGeneratorConfig(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerIn
 	}
 	return string(yamlConfig), nil
 }
+
+func (r *AtomReconciler) getRenderedFeeds(atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, blobEndpoint string) (map[string]string, error) {
+	atomGeneratorConfig, err := generator.MapAtomV3ToAtomGeneratorConfig(*atom, *ownerInfo, blobEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map the V3 atom to generator config: %w", err)
+	}
+
+	options := generator.GetRenderOptions(*atom)
+	options.LinkData = r.LinkData
+	renderedFeeds, err := generator.RenderFeeds(atomGeneratorConfig, options, r.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the feeds: %w", err)
+	}
+	return renderedFeeds, nil
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// AtomOperatorConfigReconciler reconciles the Atoms whose settings changed with the AtomOperatorConfig.
+// The settings themselves are read from the cache wherever they are used.
+type AtomOperatorConfigReconciler struct {
+	client.Client
+	// Settings reads the AtomOperatorConfig that is used, other AtomOperatorConfigs are ignored
+	Settings *pdoknlv3.SettingsReader
+	// AtomEvents receives the Atoms that have to be reconciled again
+	AtomEvents chan<- event.GenericEvent
+
+	// operatorConfig is the spec of the AtomOperatorConfig at the previous reconcile, nil when there was none
+	operatorConfig *pdoknlv3.AtomOperatorConfigSpec
+}
+
+// +kubebuilder:rbac:groups=pdok.nl,resources=atomoperatorconfigs,verbs=get;list;watch
+
+func (r *AtomOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	lgr := logf.FromContext(ctx)
+
+	operatorConfig, err := r.Settings.GetOperatorConfig(ctx)
+	if err != nil {
+		return result, err
+	}
+	atomList := &pdoknlv3.AtomList{}
+	if err = r.List(ctx, atomList); err != nil {
+		return result, fmt.Errorf("unable to list the atoms: %w", err)
+	}
+	changedNamespaces := make(map[string]bool)
+	for _, atom := range atomList.Items {
+		namespace := atom.Namespace
+		if _, ok := changedNamespaces[namespace]; !ok {
+			changedNamespaces[namespace] = !equality.Semantic.DeepEqual(
+				pdoknlv3.GetSettings(r.Settings.Flags, r.operatorConfig, namespace),
+				pdoknlv3.GetSettings(r.Settings.Flags, operatorConfig, namespace),
+			)
+			if changedNamespaces[namespace] {
+				lgr.Info("Settings of namespace changed, reconciling its atoms", "namespace", namespace)
+			}
+		}
+		if changedNamespaces[namespace] {
+			r.AtomEvents <- event.GenericEvent{Object: &atom}
+		}
+	}
+	r.operatorConfig = operatorConfig
+	return result, nil
+}
+
+// getSettings returns the settings for the Atoms in the namespace, with the images and CSP of the reconciler as defaults
+func (r *AtomReconciler) getSettings(ctx context.Context, namespace string) (pdoknlv3.OperatorSettings, error) {
+	settings, err := r.Settings.GetSettings(ctx, namespace)
+	if err != nil {
+		return settings, err
+	}
+	settings.AtomGeneratorImage = cmp.Or(settings.AtomGeneratorImage, r.AtomGeneratorImage)
+	settings.LighttpdImage = cmp.Or(settings.LighttpdImage, r.LighttpdImage)
+	settings.CSP = cmp.Or(settings.CSP, r.CSP)
+	return settings, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AtomOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&pdoknlv3.AtomOperatorConfig{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == r.Settings.ConfigName
+		}))).
+		Complete(r)
+}
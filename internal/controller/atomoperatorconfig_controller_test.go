@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatorutils "github.com/pdok/smooth-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func Test_AtomOperatorConfigReconciler(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, pdoknlv3.AddToScheme(scheme))
+
+	production := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "atom", Namespace: "production"}}
+	acceptance := &pdoknlv3.Atom{ObjectMeta: metav1.ObjectMeta{Name: "atom", Namespace: "acceptance"}}
+	operatorConfig := &pdoknlv3.AtomOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "atom-operator"},
+		Spec: pdoknlv3.AtomOperatorConfigSpec{
+			AtomOperatorSettings: pdoknlv3.AtomOperatorSettings{LighttpdImage: smoothoperatorutils.Pointer("lighttpd:2")},
+			NamespaceOverrides: []pdoknlv3.NamespaceOverride{{
+				Namespace:            "acceptance",
+				AtomOperatorSettings: pdoknlv3.AtomOperatorSettings{LighttpdImage: smoothoperatorutils.Pointer("lighttpd:3")},
+			}},
+		},
+	}
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(production, acceptance, operatorConfig).Build()
+	atomEvents := make(chan event.GenericEvent, 10)
+	settings := &pdoknlv3.SettingsReader{Reader: fakeClient, ConfigName: "atom-operator"}
+	configReconciler := AtomOperatorConfigReconciler{Client: fakeClient, Settings: settings, AtomEvents: atomEvents}
+	atomReconciler := AtomReconciler{LighttpdImage: "lighttpd:1", CSP: "default-src 'self'", Settings: settings}
+	getSettings := func(namespace string) pdoknlv3.OperatorSettings {
+		namespaceSettings, err := atomReconciler.getSettings(ctx, namespace)
+		require.NoError(t, err)
+		return namespaceSettings
+	}
+
+	// reconcile returns the namespaces of the Atoms that are reconciled again
+	reconcile := func() []string {
+		_, err := configReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(operatorConfig)})
+		require.NoError(t, err)
+		var namespaces []string
+		for len(atomEvents) > 0 {
+			namespaces = append(namespaces, (<-atomEvents).Object.GetNamespace())
+		}
+		return namespaces
+	}
+
+	require.ElementsMatch(t, []string{"production", "acceptance"}, reconcile())
+	require.Equal(t, "lighttpd:2", getSettings("production").LighttpdImage)
+	require.Equal(t, "lighttpd:3", getSettings("acceptance").LighttpdImage)
+	require.Equal(t, "default-src 'self'", getSettings("acceptance").CSP, "settings that are not configured fall back to the flags")
+
+	operatorConfig.Spec.NamespaceOverrides[0].LighttpdImage = smoothoperatorutils.Pointer("lighttpd:4")
+	require.NoError(t, fakeClient.Update(ctx, operatorConfig))
+	require.Equal(t, []string{"acceptance"}, reconcile(), "only the Atoms whose settings changed are reconciled")
+
+	require.Empty(t, reconcile(), "nothing changed")
+
+	require.NoError(t, fakeClient.Delete(ctx, operatorConfig))
+	require.ElementsMatch(t, []string{"production", "acceptance"}, reconcile())
+	require.Equal(t, "lighttpd:1", getSettings("acceptance").LighttpdImage)
+}
@@ -0,0 +1,103 @@
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // Content-MD5 is only used to detect changes
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// Container publishes files to a prefix in an Azure (compatible) blob container
+type Container struct {
+	client *container.Client
+	name   string
+	prefix string
+}
+
+// NewContainer creates a Container for containerAndPrefix, formatted as container or container/prefix
+func NewContainer(connectionString, containerAndPrefix string) (*Container, error) {
+	name, prefix, _ := strings.Cut(strings.Trim(containerAndPrefix, "/"), "/")
+	if name == "" {
+		return nil, errors.New("no container is given")
+	}
+
+	client, err := azblob.NewClientFromConnectionString(connectionString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create blob storage client: %w", err)
+	}
+	return &Container{
+		client: client.ServiceClient().NewContainerClient(name),
+		name:   name,
+		prefix: prefix,
+	}, nil
+}
+
+// GetURLPath returns the path of dir as served by the blob storage, /container/prefix/dir
+func (c *Container) GetURLPath(dir string) string {
+	return path.Join("/", c.name, c.prefix, dir)
+}
+
+// Publish makes dir contain exactly the given files, keyed by file name.
+// Only files that have changed are uploaded with the content type for their file name, files that are no longer given are deleted.
+// It returns whether any file was uploaded or deleted.
+func (c *Container) Publish(ctx context.Context, dir string, files map[string]string, getContentType func(fileName string) string) (changed bool, err error) {
+	dirPrefix := path.Join(c.prefix, dir) + "/"
+
+	published := make(map[string][]byte)
+	pager := c.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &dirPrefix})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return false, fmt.Errorf("could not list the blobs in %s: %w", c.GetURLPath(dir), err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			var contentMD5 []byte
+			if item.Properties != nil {
+				contentMD5 = item.Properties.ContentMD5
+			}
+			published[*item.Name] = contentMD5
+		}
+	}
+
+	for fileName, content := range files {
+		blobName := dirPrefix + fileName
+		contentMD5 := md5.Sum([]byte(content)) //nolint:gosec
+		if existing, ok := published[blobName]; ok && bytes.Equal(existing, contentMD5[:]) {
+			continue
+		}
+
+		contentType := getContentType(fileName)
+		_, err = c.client.NewBlockBlobClient(blobName).UploadBuffer(ctx, []byte(content), &blockblob.UploadBufferOptions{
+			HTTPHeaders: &blob.HTTPHeaders{
+				BlobContentType: &contentType,
+				BlobContentMD5:  contentMD5[:],
+			},
+		})
+		if err != nil {
+			return changed, fmt.Errorf("could not upload %s: %w", c.GetURLPath(path.Join(dir, fileName)), err)
+		}
+		changed = true
+	}
+
+	for blobName := range published {
+		if _, ok := files[strings.TrimPrefix(blobName, dirPrefix)]; ok {
+			continue
+		}
+		if _, err = c.client.NewBlobClient(blobName).Delete(ctx, nil); err != nil {
+			return changed, fmt.Errorf("could not delete %s: %w", path.Join("/", c.name, blobName), err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
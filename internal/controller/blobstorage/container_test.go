@@ -0,0 +1,142 @@
+package blobstorage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testAccount = "devstoreaccount1"
+
+// fakeBlobStorage is a local stand-in for the Put Blob, Delete Blob and List Blobs operations of Azure blob storage
+type fakeBlobStorage struct {
+	mu      sync.Mutex
+	blobs   map[string]fakeBlob
+	uploads []string
+}
+
+type fakeBlob struct {
+	content     string
+	contentType string
+	contentMD5  string
+}
+
+func (f *fakeBlobStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/"+testAccount+"/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("comp") == "list":
+		f.list(w, name, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		f.blobs[name] = fakeBlob{
+			content:     string(content),
+			contentType: r.Header.Get("x-ms-blob-content-type"),
+			contentMD5:  r.Header.Get("x-ms-blob-content-md5"),
+		}
+		f.uploads = append(f.uploads, name)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeBlobStorage) list(w http.ResponseWriter, containerName, prefix string) {
+	type properties struct {
+		ContentMD5 string `xml:"Content-MD5"`
+	}
+	type blobItem struct {
+		Name       string     `xml:"Name"`
+		Properties properties `xml:"Properties"`
+	}
+	result := struct {
+		XMLName xml.Name   `xml:"EnumerationResults"`
+		Blobs   []blobItem `xml:"Blobs>Blob"`
+	}{}
+	for _, name := range slices.Sorted(maps.Keys(f.blobs)) {
+		blobName, ok := strings.CutPrefix(name, containerName+"/")
+		if ok && strings.HasPrefix(blobName, prefix) {
+			result.Blobs = append(result.Blobs, blobItem{Name: blobName, Properties: properties{ContentMD5: f.blobs[name].contentMD5}})
+		}
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func TestContainerPublish(t *testing.T) {
+	storage := &fakeBlobStorage{blobs: map[string]fakeBlob{
+		"atom/other/default/atom/index.xml":     {content: "other prefix"},
+		"atom/feeds/default/atom/obsolete.xml":  {content: "obsolete"},
+		"atom/feeds/default/atom-2/index.xml":   {content: "other atom"},
+		"atom/feeds/default/atom/unchanged.xml": {content: "unchanged", contentMD5: "jXs9a4PApRfqwH4arJS3cw=="},
+	}}
+	server := httptest.NewServer(storage)
+	defer server.Close()
+
+	connectionString := "DefaultEndpointsProtocol=http;AccountName=" + testAccount +
+		";AccountKey=" + base64.StdEncoding.EncodeToString([]byte("key")) +
+		";BlobEndpoint=" + server.URL + "/" + testAccount + ";"
+	c, err := NewContainer(connectionString, "atom/feeds")
+	if err != nil {
+		t.Fatalf("NewContainer() error = %v", err)
+	}
+	if got := c.GetURLPath("default/atom"); got != "/atom/feeds/default/atom" {
+		t.Errorf("GetURLPath() = %s, want /atom/feeds/default/atom", got)
+	}
+
+	getContentType := func(string) string { return "application/atom+xml" }
+	files := map[string]string{
+		"index.xml":     "index",
+		"unchanged.xml": "unchanged",
+	}
+	changed, err := c.Publish(context.Background(), "default/atom", files, getContentType)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if !changed {
+		t.Error("Publish() = false, want true")
+	}
+
+	if !slices.Equal(storage.uploads, []string{"atom/feeds/default/atom/index.xml"}) {
+		t.Errorf("Publish() uploaded %v, want only the changed index.xml", storage.uploads)
+	}
+	if got := storage.blobs["atom/feeds/default/atom/index.xml"]; got.content != "index" || got.contentType != "application/atom+xml" {
+		t.Errorf("Publish() stored %+v, want index with type application/atom+xml", got)
+	}
+	wantBlobs := []string{
+		"atom/feeds/default/atom-2/index.xml",
+		"atom/feeds/default/atom/index.xml",
+		"atom/feeds/default/atom/unchanged.xml",
+		"atom/other/default/atom/index.xml",
+	}
+	if got := slices.Sorted(maps.Keys(storage.blobs)); !slices.Equal(got, wantBlobs) {
+		t.Errorf("Publish() left %v, want %v", got, wantBlobs)
+	}
+
+	storage.uploads = nil
+	if changed, err = c.Publish(context.Background(), "default/atom", files, getContentType); err != nil || changed {
+		t.Errorf("Publish() again = %t, %v, want false without error", changed, err)
+	}
+	if len(storage.uploads) > 0 {
+		t.Errorf("Publish() again uploaded %v, want nothing", storage.uploads)
+	}
+}
+
+func TestNewContainerWithoutName(t *testing.T) {
+	if _, err := NewContainer("", "/"); err == nil {
+		t.Error("NewContainer() should fail without container")
+	}
+}
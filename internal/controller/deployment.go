@@ -29,8 +29,7 @@ func getBareDeployment(obj metav1.Object) *appsv1.Deployment {
 	}
 }
 
-//nolint:funlen
-func (r *AtomReconciler) mutateDeployment(atom *pdoknlv3.Atom, deployment *appsv1.Deployment, configMapName string) error {
+func (r *AtomReconciler) mutateDeployment(atom *pdoknlv3.Atom, settings pdoknlv3.OperatorSettings, deployment *appsv1.Deployment, configMapName string) error {
 	deployment.Labels = getObjectLabels(atom, deployment.Labels)
 
 	podTemplateAnnotations := smoothutil.CloneOrEmptyMap(deployment.Spec.Template.GetAnnotations())
@@ -40,16 +39,7 @@ func (r *AtomReconciler) mutateDeployment(atom *pdoknlv3.Atom, deployment *appsv
 
 	deployment.Spec.Selector = getLabelSelector(atom)
 
-	deployment.Spec.MinReadySeconds = 0
-	deployment.Spec.Strategy = appsv1.DeploymentStrategy{
-		Type: appsv1.RollingUpdateDeploymentStrategyType,
-		RollingUpdate: &appsv1.RollingUpdateDeployment{
-			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 0},
-			MaxSurge:       &intstr.IntOrString{Type: intstr.Int, IntVal: 4},
-		},
-	}
-	deployment.Spec.RevisionHistoryLimit = smoothutil.Pointer(int32(1))
-	deployment.Spec.Replicas = smoothutil.Pointer(int32(2))
+	setDeploymentRollout(deployment)
 
 	podTemplateSpec := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,77 +48,24 @@ func (r *AtomReconciler) mutateDeployment(atom *pdoknlv3.Atom, deployment *appsv
 		},
 		Spec: corev1.PodSpec{
 			Volumes: []corev1.Volume{
-				{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
 				{Name: "socket", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
 				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{Name: configMapName}}},
 				},
 			},
-			InitContainers: []corev1.Container{
-				{
-					Name:            "atom-generator",
-					ImagePullPolicy: corev1.PullIfNotPresent,
-					Command:         []string{"./atom"},
-					Args:            []string{"-f=" + srvDir + "/config/" + configFileName, "-o=" + srvDir + "/data"},
-
-					VolumeMounts: []corev1.VolumeMount{
-						{Name: "data", MountPath: srvDir + "/data"},
-						{Name: "config", MountPath: srvDir + "/config"},
-					},
-				},
-			},
 			Containers: []corev1.Container{
-				{
-					Name: "atom-service",
-					Ports: []corev1.ContainerPort{
-						{
-							ContainerPort: atomPortNr,
-						},
-					},
-					ImagePullPolicy: corev1.PullIfNotPresent,
-					LivenessProbe: &corev1.Probe{
-						ProbeHandler: corev1.ProbeHandler{
-							HTTPGet: &corev1.HTTPGetAction{
-								Path:   "/index.xml",
-								Port:   intstr.FromInt32(atomPortNr),
-								Scheme: corev1.URISchemeHTTP,
-							},
-						},
-						InitialDelaySeconds: 5,
-						TimeoutSeconds:      5,
-						PeriodSeconds:       10,
-					},
-					ReadinessProbe: &corev1.Probe{
-						ProbeHandler: corev1.ProbeHandler{
-							HTTPGet: &corev1.HTTPGetAction{
-								Path:   "/index.xml",
-								Port:   intstr.FromInt32(atomPortNr),
-								Scheme: corev1.URISchemeHTTP,
-							},
-						},
-						InitialDelaySeconds: 5,
-						TimeoutSeconds:      5,
-						PeriodSeconds:       10,
-					},
-					Resources: corev1.ResourceRequirements{
-						Limits: corev1.ResourceList{
-							corev1.ResourceMemory: resource.MustParse("64M"),
-						},
-						Requests: corev1.ResourceList{
-							corev1.ResourceCPU: resource.MustParse("0.01"),
-						},
-					},
-					VolumeMounts: []corev1.VolumeMount{
-						{Name: "socket", MountPath: "/tmp", ReadOnly: false},
-						{Name: "data", MountPath: "/var/www/"},
-					},
-				},
+				getAtomServiceContainer(getLighttpdImage(atom, settings), httpGetProbeHandler("/index.xml")),
 			},
 		},
 	}
 
-	podTemplateSpec.Spec.InitContainers[0].Image = r.AtomGeneratorImage
-	podTemplateSpec.Spec.Containers[0].Image = r.LighttpdImage
+	if r.LegacyAtomGenerator {
+		addAtomGeneratorInitContainer(&podTemplateSpec.Spec, getAtomGeneratorImage(atom, settings))
+	} else {
+		// The feeds are rendered by the operator and served straight from the ConfigMap
+		podTemplateSpec.Spec.Containers[0].VolumeMounts = append(podTemplateSpec.Spec.Containers[0].VolumeMounts,
+			corev1.VolumeMount{Name: "config", MountPath: "/var/www/", ReadOnly: true})
+	}
 	deployment.Spec.Template = podTemplateSpec
 
 	if err := smoothutil.EnsureSetGVK(r.Client, deployment, deployment); err != nil {
@@ -137,3 +74,104 @@ func (r *AtomReconciler) mutateDeployment(atom *pdoknlv3.Atom, deployment *appsv
 	return ctrl.SetControllerReference(atom, deployment, r.Scheme)
 
 }
+
+// getLighttpdImage returns the lighttpd image of the Atom, which is the image of the settings unless it is overridden
+func getLighttpdImage(atom *pdoknlv3.Atom, settings pdoknlv3.OperatorSettings) string {
+	if atom.Spec.Images != nil && atom.Spec.Images.Lighttpd != nil {
+		return *atom.Spec.Images.Lighttpd
+	}
+	return settings.LighttpdImage
+}
+
+// getAtomGeneratorImage returns the atom-generator image of the Atom, which is the image of the settings unless it is overridden
+func getAtomGeneratorImage(atom *pdoknlv3.Atom, settings pdoknlv3.OperatorSettings) string {
+	if atom.Spec.Images != nil && atom.Spec.Images.AtomGenerator != nil {
+		return *atom.Spec.Images.AtomGenerator
+	}
+	return settings.AtomGeneratorImage
+}
+
+// addAtomGeneratorInitContainer lets an init container generate the feeds from the generator config in the ConfigMap
+func addAtomGeneratorInitContainer(podSpec *corev1.PodSpec, image string) {
+	podSpec.Volumes = append([]corev1.Volume{
+		{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}, podSpec.Volumes...)
+	podSpec.InitContainers = []corev1.Container{
+		{
+			Name:            "atom-generator",
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"./atom"},
+			Args:            []string{"-f=" + srvDir + "/config/" + configFileName, "-o=" + srvDir + "/data"},
+			// The last log lines end up in the status of the Atom when the generator fails
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "data", MountPath: srvDir + "/data"},
+				{Name: "config", MountPath: srvDir + "/config"},
+			},
+		},
+	}
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: "data", MountPath: "/var/www/"})
+}
+
+func setDeploymentRollout(deployment *appsv1.Deployment) {
+	deployment.Spec.MinReadySeconds = 0
+	deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 0},
+			MaxSurge:       &intstr.IntOrString{Type: intstr.Int, IntVal: 4},
+		},
+	}
+	deployment.Spec.RevisionHistoryLimit = smoothutil.Pointer(int32(1))
+	deployment.Spec.Replicas = smoothutil.Pointer(int32(2))
+}
+
+// getAtomServiceContainer returns the lighttpd container, the feeds still have to be mounted at /var/www/
+func getAtomServiceContainer(image string, probeHandler corev1.ProbeHandler) corev1.Container {
+	return corev1.Container{
+		Name:  "atom-service",
+		Image: image,
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: atomPortNr,
+			},
+		},
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler:        probeHandler,
+			InitialDelaySeconds: 5,
+			TimeoutSeconds:      5,
+			PeriodSeconds:       10,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler:        probeHandler,
+			InitialDelaySeconds: 5,
+			TimeoutSeconds:      5,
+			PeriodSeconds:       10,
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("64M"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("0.01"),
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "socket", MountPath: "/tmp", ReadOnly: false},
+		},
+	}
+}
+
+func httpGetProbeHandler(path string) corev1.ProbeHandler {
+	return corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   path,
+			Port:   intstr.FromInt32(atomPortNr),
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+}
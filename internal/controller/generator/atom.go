@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"encoding/xml"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+)
+
+// atomFeed is the XML of an atomfeed.Feed, with the elements the atom-generator cannot render, like the categories of the feed
+type atomFeed struct {
+	XMLName    xml.Name `xml:"feed"`
+	Xmlns      string   `xml:"xmlns,attr"`
+	Georss     string   `xml:"xmlns:georss,attr,omitempty"`
+	InspireDls string   `xml:"xmlns:inspire_dls,attr,omitempty"`
+	GML        string   `xml:"xmlns:gml,attr,omitempty"`
+	Lang       *string  `xml:"xml:lang,attr,omitempty"`
+
+	ID       string `xml:"id"`
+	Title    string `xml:"title"`
+	Subtitle string `xml:"subtitle"`
+
+	Self        *atomfeed.Link `xml:"self,omitempty"`
+	Describedby *atomfeed.Link `xml:"describedby,omitempty"`
+	Search      *atomfeed.Link `xml:"search,omitempty"`
+	Up          *atomfeed.Link `xml:"up,omitempty"`
+
+	Link []atomfeed.Link `xml:"link"`
+
+	Rights   string          `xml:"rights"`
+	Updated  *string         `xml:"updated"`
+	Author   atomfeed.Author `xml:"author"`
+	Category []Category      `xml:"category"`
+	Entry    []atomEntry     `xml:"entry"`
+}
+
+// atomEntry is the XML of an atomfeed.Entry, with a georss:where instead of a georss:polygon if there is one
+type atomEntry struct {
+	ID                                string              `xml:"id"`
+	Title                             string              `xml:"title,omitempty"`
+	Content                           string              `xml:"content,omitempty"`
+	Summary                           string              `xml:"summary,omitempty"`
+	Link                              []atomfeed.Link     `xml:"link"`
+	Rights                            string              `xml:"rights,omitempty"`
+	Updated                           *string             `xml:"updated"`
+	Polygon                           string              `xml:"georss:polygon,omitempty"`
+	Where                             *Where              `xml:"georss:where,omitempty"`
+	Category                          []atomfeed.Category `xml:"category"`
+	SpatialDatasetIdentifierCode      *string             `xml:"inspire_dls:spatial_dataset_identifier_code,omitempty"`
+	SpatialDatasetIdentifierNamespace *string             `xml:"inspire_dls:spatial_dataset_identifier_namespace,omitempty"`
+}
+
+// generateATOM renders the feed the way atomfeed.Feed.GenerateATOM does, with the categories of the feed and the georss:where
+// of the entries by entry ID
+func generateATOM(feed atomfeed.Feed, categories []Category, wheres map[string]Where) ([]byte, error) {
+	xmlFeed := atomFeed{
+		Xmlns:       feed.Xmlns,
+		Georss:      feed.Georss,
+		InspireDls:  feed.InspireDls,
+		Lang:        feed.Lang,
+		ID:          feed.ID,
+		Title:       feed.Title,
+		Subtitle:    feed.Subtitle,
+		Self:        feed.Self,
+		Describedby: feed.Describedby,
+		Search:      feed.Search,
+		Up:          feed.Up,
+		Link:        feed.Link,
+		Rights:      feed.Rights,
+		Updated:     feed.Updated,
+		Author:      feed.Author,
+		Category:    categories,
+	}
+	for _, entry := range feed.Entry {
+		xmlEntry := atomEntry{
+			ID:                                entry.ID,
+			Title:                             entry.Title,
+			Content:                           entry.Content,
+			Summary:                           entry.Summary,
+			Link:                              entry.Link,
+			Rights:                            entry.Rights,
+			Updated:                           entry.Updated,
+			Polygon:                           entry.Polygon,
+			Category:                          entry.Category,
+			SpatialDatasetIdentifierCode:      entry.SpatialDatasetIdentifierCode,
+			SpatialDatasetIdentifierNamespace: entry.SpatialDatasetIdentifierNamespace,
+		}
+		if where, ok := wheres[entry.ID]; ok {
+			xmlEntry.Polygon = ""
+			xmlEntry.Where = &where
+			xmlFeed.GML = GMLNamespace
+		}
+		xmlFeed.Entry = append(xmlFeed.Entry, xmlEntry)
+	}
+
+	feedXML, err := xml.MarshalIndent(xmlFeed, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), feed.StyleSheet()...), feedXML...), nil
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"encoding/xml"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+// Category is an atom:category of a feed. The generator config only has categories on entries and without scheme,
+// so these are passed to generateATOM next to it.
+type Category struct {
+	XMLName xml.Name `xml:"category"`
+	Term    string   `xml:"term,attr"`
+	Label   string   `xml:"label,attr,omitempty"`
+	Scheme  string   `xml:"scheme,attr,omitempty"`
+}
+
+// GetFeedCategories returns the categories of the keywords and INSPIRE themes of the service and dataset feeds by feed ID.
+// The pages and archive of a dataset feed all have the categories of the dataset feed.
+func GetFeedCategories(atom pdoknlv3.Atom) map[string][]Category {
+	feedCategories := make(map[string][]Category)
+	if categories := getCategories(atom.Spec.Service.Keywords, atom.Spec.Service.InspireThemes); len(categories) > 0 {
+		feedCategories[atom.Spec.Service.BaseURL.JoinPath("index.xml").String()] = categories
+	}
+	for _, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		categories := getCategories(datasetFeed.Keywords, datasetFeed.InspireThemes)
+		if len(categories) == 0 {
+			continue
+		}
+		for _, fileName := range datasetFeed.GetFeedFileNames() {
+			feedCategories[atom.Spec.Service.BaseURL.JoinPath(fileName).String()] = categories
+		}
+	}
+	return feedCategories
+}
+
+func getCategories(keywords []string, inspireThemes []pdoknlv3.InspireTheme) []Category {
+	var categories []Category
+	for _, keyword := range keywords {
+		categories = append(categories, Category{Term: keyword})
+	}
+	for _, theme := range inspireThemes {
+		categories = append(categories, Category{Term: theme.URI.String(), Label: theme.GetLabel(), Scheme: pdoknlv3.InspireThemeScheme})
+	}
+	return categories
+}
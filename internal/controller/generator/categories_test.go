@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func TestGetFeedCategories(t *testing.T) {
+	baseURL, _ := url.Parse("https://test.com/path/")
+	auURI, _ := url.Parse("http://inspire.ec.europa.eu/theme/au")
+	cpURI, _ := url.Parse("http://inspire.ec.europa.eu/theme/cp")
+
+	atom := pdoknlv3.Atom{Spec: pdoknlv3.AtomSpec{Service: pdoknlv3.Service{
+		BaseURL:       smoothoperatormodel.URL{URL: baseURL},
+		Keywords:      []string{"downloads"},
+		InspireThemes: []pdoknlv3.InspireTheme{{URI: smoothoperatormodel.URL{URL: auURI}, Label: smoothutil.Pointer("Bestuurlijke eenheden")}},
+		DatasetFeeds: []pdoknlv3.DatasetFeed{
+			{
+				TechnicalName: "feed-1",
+				InspireThemes: []pdoknlv3.InspireTheme{{URI: smoothoperatormodel.URL{URL: cpURI}}},
+				Archive:       &pdoknlv3.Archive{},
+			},
+			{TechnicalName: "feed-2"},
+		},
+	}}}
+
+	datasetCategories := []Category{{Term: "http://inspire.ec.europa.eu/theme/cp", Label: "Cadastral parcels", Scheme: pdoknlv3.InspireThemeScheme}}
+	want := map[string][]Category{
+		"https://test.com/path/index.xml": {
+			{Term: "downloads"},
+			{Term: "http://inspire.ec.europa.eu/theme/au", Label: "Bestuurlijke eenheden", Scheme: pdoknlv3.InspireThemeScheme},
+		},
+		"https://test.com/path/feed-1.xml":         datasetCategories,
+		"https://test.com/path/feed-1-archive.xml": datasetCategories,
+	}
+	if got := GetFeedCategories(atom); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFeedCategories() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateATOMCategories(t *testing.T) {
+	categories := []Category{
+		{Term: "downloads & more"},
+		{Term: "http://inspire.ec.europa.eu/theme/au", Label: "Administrative units", Scheme: pdoknlv3.InspireThemeScheme},
+	}
+	rendered := "\n" +
+		` <category term="downloads &amp; more"></category>` + "\n" +
+		` <category term="http://inspire.ec.europa.eu/theme/au" label="Administrative units" scheme="http://inspire.ec.europa.eu/theme"></category>`
+
+	tests := []struct {
+		name       string
+		entries    []atomfeed.Entry
+		categories []Category
+		want       string
+	}{
+		{
+			name:       "before_entries",
+			entries:    []atomfeed.Entry{{ID: "https://test.com/path/entry.xml"}},
+			categories: categories,
+			want:       "</author>" + rendered + "\n <entry>",
+		},
+		{
+			name:       "without_entries",
+			categories: categories,
+			want:       "</author>" + rendered + "\n</feed>",
+		},
+		{
+			name: "without_categories",
+			want: "</author>\n</feed>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := atomfeed.Feed{Xmlns: "http://www.w3.org/2005/Atom", ID: "https://test.com/path/feed.xml", Entry: tt.entries}
+			got, err := generateATOM(feed, tt.categories, nil)
+			if err != nil {
+				t.Fatalf("generateATOM() error = %v", err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("generateATOM() = %s, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+}
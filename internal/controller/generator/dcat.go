@@ -0,0 +1,559 @@
+package generator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+const (
+	mediaTypesURI      = "https://www.iana.org/assignments/media-types/"
+	languagesURI       = "http://publications.europa.eu/resource/authority/language/"
+	wktLiteralType     = "http://www.opengis.net/ont/geosparql#wktLiteral"
+	dateTimeType       = "http://www.w3.org/2001/XMLSchema#dateTime"
+	nonNegativeIntType = "http://www.w3.org/2001/XMLSchema#nonNegativeInteger"
+)
+
+// dcatLanguages maps the languages of feeds to the EU language authority
+var dcatLanguages = map[string]string{"nl": "NLD", "en": "ENG", "de": "DEU", "fr": "FRA"}
+
+var dcatNamespaces = map[string]string{
+	"dcat":  "http://www.w3.org/ns/dcat#",
+	"dct":   "http://purl.org/dc/terms/",
+	"foaf":  "http://xmlns.com/foaf/0.1/",
+	"vcard": "http://www.w3.org/2006/vcard/ns#",
+	"rdfs":  "http://www.w3.org/2000/01/rdf-schema#",
+}
+
+// dcatCatalog is the service feed of an Atom as DCAT-AP catalog, with its dataset feeds as datasets
+type dcatCatalog struct {
+	ID          string
+	Title       string
+	Description string
+	Language    string
+	Modified    string
+	Homepage    string
+	Publisher   atomfeed.Author
+	Datasets    []dcatDataset
+}
+
+type dcatDataset struct {
+	ID            string
+	Title         string
+	Description   string
+	Identifier    string
+	Language      string
+	Modified      string
+	LandingPage   string
+	Pages         []string
+	License       string
+	Rights        string
+	BBox          string
+	ContactPoint  atomfeed.Author
+	Distributions []dcatDistribution
+}
+
+type dcatDistribution struct {
+	ID        string
+	Title     string
+	URL       string
+	MediaType string
+	ByteSize  string
+	Modified  string
+	License   string
+	Rights    string
+	BBox      string
+}
+
+// RenderDCAT renders the DCAT-AP (GeoDCAT-AP) document of the feeds as JSON-LD and as RDF/XML.
+// Only the downloads of the dataset feeds and their next pages are distributions, archived downloads are left out.
+func RenderDCAT(feeds []atomfeed.Feed) (jsonLD string, rdfXML string, err error) {
+	catalog, err := getDCATCatalog(feeds)
+	if err != nil {
+		return "", "", err
+	}
+	if jsonLD, err = renderDCATJSONLD(catalog); err != nil {
+		return "", "", err
+	}
+	if rdfXML, err = renderDCATRDF(catalog); err != nil {
+		return "", "", err
+	}
+	return jsonLD, rdfXML, nil
+}
+
+func getDCATCatalog(feeds []atomfeed.Feed) (dcatCatalog, error) {
+	feedsByID := make(map[string]atomfeed.Feed)
+	var serviceFeed *atomfeed.Feed
+	for i, feed := range feeds {
+		feedsByID[feed.ID] = feed
+		if strings.HasSuffix(feed.ID, "/index.xml") {
+			serviceFeed = &feeds[i]
+		}
+	}
+	if serviceFeed == nil {
+		return dcatCatalog{}, fmt.Errorf("there is no service feed to map to a DCAT catalog")
+	}
+
+	catalog := dcatCatalog{
+		ID:          strings.TrimSuffix(serviceFeed.ID, "index.xml"),
+		Title:       unescapeQuotes(serviceFeed.Title),
+		Description: unescapeQuotes(serviceFeed.Subtitle),
+		Language:    getDCATLanguage(serviceFeed.Lang),
+		Modified:    valueOrEmpty(serviceFeed.Updated),
+		Homepage:    pdoknlv3.GetHTMLFileName(serviceFeed.ID),
+		Publisher:   serviceFeed.Author,
+	}
+	for _, entry := range serviceFeed.Entry {
+		var feedID string
+		for _, link := range entry.Link {
+			if link.Rel == "alternate" && link.Type == "application/atom+xml" {
+				feedID = link.Href
+			}
+		}
+		datasetFeed, ok := feedsByID[feedID]
+		if !ok {
+			continue
+		}
+
+		dataset := dcatDataset{
+			ID:           strings.TrimSuffix(feedID, ".xml"),
+			Title:        unescapeQuotes(entry.Title),
+			Description:  unescapeQuotes(entry.Summary),
+			Identifier:   valueOrEmpty(entry.SpatialDatasetIdentifierCode),
+			Language:     catalog.Language,
+			Modified:     valueOrEmpty(entry.Updated),
+			LandingPage:  pdoknlv3.GetHTMLFileName(feedID),
+			BBox:         georssPolygonToWKT(entry.Polygon),
+			ContactPoint: datasetFeed.Author,
+		}
+		dataset.License, dataset.Rights = getDCATLicense(datasetFeed)
+		for _, link := range entry.Link {
+			if link.Rel == "describedby" {
+				dataset.Pages = append(dataset.Pages, link.Href)
+			}
+		}
+
+		// Every page of a paged dataset feed links to the next one
+		for page, visited := &datasetFeed, map[string]bool{}; page != nil && !visited[page.ID]; page = getNextPage(*page, feedsByID) {
+			visited[page.ID] = true
+			for _, datasetEntry := range page.Entry {
+				for _, link := range datasetEntry.Link {
+					if !strings.HasPrefix(link.Href, catalog.ID+"downloads/") {
+						continue
+					}
+					distribution := dcatDistribution{
+						ID:        link.Href,
+						Title:     unescapeQuotes(link.Title),
+						URL:       link.Href,
+						MediaType: getDCATMediaType(link.Type),
+						ByteSize:  link.Length,
+						Modified:  valueOrEmpty(link.Time),
+						License:   dataset.License,
+						Rights:    dataset.Rights,
+						BBox:      georssPolygonToWKT(datasetEntry.Polygon),
+					}
+					dataset.Distributions = append(dataset.Distributions, distribution)
+				}
+			}
+		}
+		catalog.Datasets = append(catalog.Datasets, dataset)
+	}
+	return catalog, nil
+}
+
+func getNextPage(feed atomfeed.Feed, feedsByID map[string]atomfeed.Feed) *atomfeed.Feed {
+	for _, link := range feed.Link {
+		if link.Rel == "next" {
+			if next, ok := feedsByID[link.Href]; ok {
+				return &next
+			}
+		}
+	}
+	return nil
+}
+
+// getDCATLicense returns the rel=license link of the feed as license and its rights as rights statement.
+// Without such a link rights that are a URL are the license, and other rights the rights statement.
+func getDCATLicense(feed atomfeed.Feed) (license string, rightsStatement string) {
+	for _, link := range feed.Link {
+		if link.Rel == "license" {
+			return link.Href, feed.Rights
+		}
+	}
+	if u, err := url.Parse(feed.Rights); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return feed.Rights, ""
+	}
+	return "", feed.Rights
+}
+
+func getDCATLanguage(lang *string) string {
+	if lang == nil {
+		return ""
+	}
+	if code, ok := dcatLanguages[*lang]; ok {
+		return languagesURI + code
+	}
+	return ""
+}
+
+// getDCATLanguageTag returns the language tag of literals for the language URI of the catalog
+func getDCATLanguageTag(language string) string {
+	for tag, code := range dcatLanguages {
+		if language == languagesURI+code {
+			return tag
+		}
+	}
+	return ""
+}
+
+// getDCATMediaType returns the IANA URI of the media type, without its parameters
+func getDCATMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if mediaType = strings.TrimSpace(mediaType); mediaType == "" {
+		return ""
+	}
+	return mediaTypesURI + mediaType
+}
+
+// georssPolygonToWKT converts a georss polygon of "lat lon" pairs to a WKT polygon in CRS84, which has lon lat order
+func georssPolygonToWKT(polygon string) string {
+	coordinates := strings.Fields(polygon)
+	if len(coordinates) < 8 || len(coordinates)%2 != 0 {
+		return ""
+	}
+	points := make([]string, 0, len(coordinates)/2)
+	for i := 0; i < len(coordinates); i += 2 {
+		points = append(points, coordinates[i+1]+" "+coordinates[i])
+	}
+	return "POLYGON((" + strings.Join(points, ", ") + "))"
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// region JSON-LD
+
+type jsonLDNode = map[string]any
+
+func renderDCATJSONLD(catalog dcatCatalog) (string, error) {
+	lang := getDCATLanguageTag(catalog.Language)
+	text := func(value string) any {
+		if value == "" {
+			return nil
+		}
+		if lang == "" {
+			return value
+		}
+		return jsonLDNode{"@value": value, "@language": lang}
+	}
+	typed := func(value, datatype string) any {
+		if value == "" {
+			return nil
+		}
+		return jsonLDNode{"@value": value, "@type": datatype}
+	}
+	reference := func(iri string) any {
+		if iri == "" {
+			return nil
+		}
+		return jsonLDNode{"@id": iri}
+	}
+	agent := func(author atomfeed.Author, nodeType string) any {
+		if author.Name == "" && author.Email == "" {
+			return nil
+		}
+		if nodeType == "foaf:Agent" {
+			return compact(jsonLDNode{"@type": nodeType, "foaf:name": author.Name, "foaf:mbox": reference(getMailto(author.Email))})
+		}
+		return compact(jsonLDNode{"@type": nodeType, "vcard:fn": author.Name, "vcard:hasEmail": reference(getMailto(author.Email))})
+	}
+
+	datasets := make([]any, 0, len(catalog.Datasets))
+	for _, dataset := range catalog.Datasets {
+		distributions := make([]any, 0, len(dataset.Distributions))
+		for _, distribution := range dataset.Distributions {
+			distributions = append(distributions, compact(jsonLDNode{
+				"@id":              distribution.ID,
+				"@type":            "dcat:Distribution",
+				"dct:title":        text(distribution.Title),
+				"dcat:accessURL":   reference(distribution.URL),
+				"dcat:downloadURL": reference(distribution.URL),
+				"dcat:mediaType":   reference(distribution.MediaType),
+				"dcat:byteSize":    typed(distribution.ByteSize, nonNegativeIntType),
+				"dct:modified":     typed(distribution.Modified, dateTimeType),
+				"dct:license":      reference(distribution.License),
+				"dct:rights":       getJSONLDRights(distribution.Rights),
+				"dcat:bbox":        typed(distribution.BBox, wktLiteralType),
+			}))
+		}
+		pages := make([]any, 0, len(dataset.Pages))
+		for _, page := range dataset.Pages {
+			pages = append(pages, reference(page))
+		}
+		datasets = append(datasets, compact(jsonLDNode{
+			"@id":               dataset.ID,
+			"@type":             "dcat:Dataset",
+			"dct:title":         text(dataset.Title),
+			"dct:description":   text(dataset.Description),
+			"dct:identifier":    nilIfEmpty(dataset.Identifier),
+			"dct:language":      reference(dataset.Language),
+			"dct:modified":      typed(dataset.Modified, dateTimeType),
+			"dcat:landingPage":  reference(dataset.LandingPage),
+			"foaf:page":         nilIfEmptySlice(pages),
+			"dct:license":       reference(dataset.License),
+			"dct:rights":        getJSONLDRights(dataset.Rights),
+			"dct:spatial":       getJSONLDLocation(typed(dataset.BBox, wktLiteralType)),
+			"dcat:contactPoint": agent(dataset.ContactPoint, "vcard:Organization"),
+			"dcat:distribution": nilIfEmptySlice(distributions),
+		}))
+	}
+
+	context := jsonLDNode{}
+	for prefix, namespace := range dcatNamespaces {
+		context[prefix] = namespace
+	}
+	document := compact(jsonLDNode{
+		"@context":        context,
+		"@id":             catalog.ID,
+		"@type":           "dcat:Catalog",
+		"dct:title":       text(catalog.Title),
+		"dct:description": text(catalog.Description),
+		"dct:language":    reference(catalog.Language),
+		"dct:modified":    typed(catalog.Modified, dateTimeType),
+		"foaf:homepage":   reference(catalog.Homepage),
+		"dct:publisher":   agent(catalog.Publisher, "foaf:Agent"),
+		"dcat:dataset":    nilIfEmptySlice(datasets),
+	})
+
+	jsonLD, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not render the DCAT document as JSON-LD: %w", err)
+	}
+	return string(jsonLD) + "\n", nil
+}
+
+func getJSONLDRights(rights string) any {
+	if rights == "" {
+		return nil
+	}
+	return jsonLDNode{"@type": "dct:RightsStatement", "rdfs:label": rights}
+}
+
+func getJSONLDLocation(bbox any) any {
+	if bbox == nil {
+		return nil
+	}
+	return jsonLDNode{"@type": "dct:Location", "dcat:bbox": bbox}
+}
+
+// compact leaves out the properties without a value
+func compact(node jsonLDNode) jsonLDNode {
+	for key, value := range node {
+		if value == nil || value == "" {
+			delete(node, key)
+		}
+	}
+	return node
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nilIfEmptySlice(values []any) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func getMailto(email string) string {
+	if email == "" {
+		return ""
+	}
+	return "mailto:" + email
+}
+
+// endregion
+
+// region RDF/XML
+
+type rdfDocument struct {
+	XMLName    xml.Name   `xml:"rdf:RDF"`
+	Namespaces []xml.Attr `xml:",attr"`
+	Catalog    rdfCatalog `xml:"dcat:Catalog"`
+}
+
+type rdfCatalog struct {
+	About       string         `xml:"rdf:about,attr"`
+	Title       *rdfLiteral    `xml:"dct:title"`
+	Description *rdfLiteral    `xml:"dct:description"`
+	Language    *rdfResource   `xml:"dct:language"`
+	Modified    *rdfLiteral    `xml:"dct:modified"`
+	Homepage    *rdfResource   `xml:"foaf:homepage"`
+	Publisher   *rdfAgent      `xml:"dct:publisher>foaf:Agent"`
+	Datasets    []rdfDatasetOf `xml:"dcat:dataset"`
+}
+
+type rdfDatasetOf struct {
+	Dataset rdfDataset `xml:"dcat:Dataset"`
+}
+
+type rdfDataset struct {
+	About         string              `xml:"rdf:about,attr"`
+	Title         *rdfLiteral         `xml:"dct:title"`
+	Description   *rdfLiteral         `xml:"dct:description"`
+	Identifier    *rdfLiteral         `xml:"dct:identifier"`
+	Language      *rdfResource        `xml:"dct:language"`
+	Modified      *rdfLiteral         `xml:"dct:modified"`
+	LandingPage   *rdfResource        `xml:"dcat:landingPage"`
+	Pages         []rdfResource       `xml:"foaf:page"`
+	License       *rdfResource        `xml:"dct:license"`
+	Rights        *rdfRights          `xml:"dct:rights>dct:RightsStatement"`
+	BBox          *rdfLiteral         `xml:"dct:spatial>dct:Location>dcat:bbox"`
+	ContactPoint  *rdfContact         `xml:"dcat:contactPoint>vcard:Organization"`
+	Distributions []rdfDistributionOf `xml:"dcat:distribution"`
+}
+
+type rdfDistributionOf struct {
+	Distribution rdfDistribution `xml:"dcat:Distribution"`
+}
+
+type rdfDistribution struct {
+	About       string       `xml:"rdf:about,attr"`
+	Title       *rdfLiteral  `xml:"dct:title"`
+	AccessURL   *rdfResource `xml:"dcat:accessURL"`
+	DownloadURL *rdfResource `xml:"dcat:downloadURL"`
+	MediaType   *rdfResource `xml:"dcat:mediaType"`
+	ByteSize    *rdfLiteral  `xml:"dcat:byteSize"`
+	Modified    *rdfLiteral  `xml:"dct:modified"`
+	License     *rdfResource `xml:"dct:license"`
+	Rights      *rdfRights   `xml:"dct:rights>dct:RightsStatement"`
+	BBox        *rdfLiteral  `xml:"dcat:bbox"`
+}
+
+type rdfLiteral struct {
+	Lang     string `xml:"xml:lang,attr,omitempty"`
+	Datatype string `xml:"rdf:datatype,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type rdfResource struct {
+	Resource string `xml:"rdf:resource,attr"`
+}
+
+type rdfRights struct {
+	Label string `xml:"rdfs:label"`
+}
+
+type rdfAgent struct {
+	Name string       `xml:"foaf:name,omitempty"`
+	Mbox *rdfResource `xml:"foaf:mbox"`
+}
+
+type rdfContact struct {
+	Name  string       `xml:"vcard:fn,omitempty"`
+	Email *rdfResource `xml:"vcard:hasEmail"`
+}
+
+func renderDCATRDF(catalog dcatCatalog) (string, error) {
+	lang := getDCATLanguageTag(catalog.Language)
+	text := func(value string) *rdfLiteral {
+		if value == "" {
+			return nil
+		}
+		return &rdfLiteral{Lang: lang, Value: value}
+	}
+	typed := func(value, datatype string) *rdfLiteral {
+		if value == "" {
+			return nil
+		}
+		return &rdfLiteral{Datatype: datatype, Value: value}
+	}
+	reference := func(iri string) *rdfResource {
+		if iri == "" {
+			return nil
+		}
+		return &rdfResource{Resource: iri}
+	}
+	rights := func(value string) *rdfRights {
+		if value == "" {
+			return nil
+		}
+		return &rdfRights{Label: value}
+	}
+
+	document := rdfDocument{
+		Namespaces: []xml.Attr{{Name: xml.Name{Local: "xmlns:rdf"}, Value: "http://www.w3.org/1999/02/22-rdf-syntax-ns#"}},
+		Catalog: rdfCatalog{
+			About:       catalog.ID,
+			Title:       text(catalog.Title),
+			Description: text(catalog.Description),
+			Language:    reference(catalog.Language),
+			Modified:    typed(catalog.Modified, dateTimeType),
+			Homepage:    reference(catalog.Homepage),
+		},
+	}
+	for _, prefix := range []string{"dcat", "dct", "foaf", "rdfs", "vcard"} {
+		document.Namespaces = append(document.Namespaces, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: dcatNamespaces[prefix]})
+	}
+	if publisher := catalog.Publisher; publisher.Name != "" || publisher.Email != "" {
+		document.Catalog.Publisher = &rdfAgent{Name: publisher.Name, Mbox: reference(getMailto(publisher.Email))}
+	}
+
+	for _, dataset := range catalog.Datasets {
+		rdfDataset := rdfDataset{
+			About:       dataset.ID,
+			Title:       text(dataset.Title),
+			Description: text(dataset.Description),
+			Identifier:  typed(dataset.Identifier, ""),
+			Language:    reference(dataset.Language),
+			Modified:    typed(dataset.Modified, dateTimeType),
+			LandingPage: reference(dataset.LandingPage),
+			License:     reference(dataset.License),
+			Rights:      rights(dataset.Rights),
+			BBox:        typed(dataset.BBox, wktLiteralType),
+		}
+		for _, page := range dataset.Pages {
+			rdfDataset.Pages = append(rdfDataset.Pages, rdfResource{Resource: page})
+		}
+		if contact := dataset.ContactPoint; contact.Name != "" || contact.Email != "" {
+			rdfDataset.ContactPoint = &rdfContact{Name: contact.Name, Email: reference(getMailto(contact.Email))}
+		}
+		for _, distribution := range dataset.Distributions {
+			rdfDataset.Distributions = append(rdfDataset.Distributions, rdfDistributionOf{Distribution: rdfDistribution{
+				About:       distribution.ID,
+				Title:       text(distribution.Title),
+				AccessURL:   reference(distribution.URL),
+				DownloadURL: reference(distribution.URL),
+				MediaType:   reference(distribution.MediaType),
+				ByteSize:    typed(distribution.ByteSize, nonNegativeIntType),
+				Modified:    typed(distribution.Modified, dateTimeType),
+				License:     reference(distribution.License),
+				Rights:      rights(distribution.Rights),
+				BBox:        typed(distribution.BBox, wktLiteralType),
+			}})
+		}
+		document.Catalog.Datasets = append(document.Catalog.Datasets, rdfDatasetOf{Dataset: rdfDataset})
+	}
+
+	rdfXML, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not render the DCAT document as RDF/XML: %w", err)
+	}
+	return xml.Header + string(rdfXML) + "\n", nil
+}
+
+// endregion
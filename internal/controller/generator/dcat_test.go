@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func getDCATTestFeeds(rights string) []atomfeed.Feed {
+	download := func(name, polygon string) atomfeed.Entry {
+		return atomfeed.Entry{
+			ID:      "https://test.com/path/" + name,
+			Title:   name,
+			Polygon: polygon,
+			Link: []atomfeed.Link{
+				{Rel: "alternate", Href: "https://test.com/path/downloads/" + name, Type: "application/geopackage+sqlite3", Length: "512", Title: name},
+				{Rel: "related", Href: "https://test.com/wfs", Type: "application/xml"},
+			},
+		}
+	}
+	return []atomfeed.Feed{
+		{
+			ID:       "https://test.com/path/index.xml",
+			Title:    `service \"title\"`,
+			Subtitle: "service subtitle",
+			Lang:     smoothutil.Pointer("nl"),
+			Updated:  smoothutil.Pointer("2006-01-02T15:04:05Z"),
+			Author:   atomfeed.Author{Name: "author", Email: "author@test.com"},
+			Rights:   rights,
+			Entry: []atomfeed.Entry{{
+				ID:                           "https://test.com/path/dataset.xml",
+				Title:                        "dataset title",
+				Summary:                      "dataset summary",
+				Polygon:                      "50 3 50 7 53 7 53 3 50 3",
+				SpatialDatasetIdentifierCode: smoothutil.Pointer("dataset-id"),
+				Link: []atomfeed.Link{
+					{Rel: "describedby", Href: "https://metadata.test/dataset", Type: "application/xml"},
+					{Rel: "alternate", Href: "https://test.com/path/dataset.xml", Type: "application/atom+xml"},
+				},
+			}},
+		},
+		{
+			ID:     "https://test.com/path/dataset.xml",
+			Title:  "dataset title",
+			Rights: rights,
+			Author: atomfeed.Author{Name: "dataset author", Email: "dataset@test.com"},
+			Link:   []atomfeed.Link{{Rel: "next", Href: "https://test.com/path/dataset-2.xml"}, {Rel: "prev-archive", Href: "https://test.com/path/dataset-archive.xml"}},
+			Entry:  []atomfeed.Entry{download("first.gpkg", "50 3 50 7 51 7 51 3 50 3")},
+		},
+		{
+			ID:    "https://test.com/path/dataset-2.xml",
+			Title: "dataset title",
+			Link:  []atomfeed.Link{{Rel: "prev", Href: "https://test.com/path/dataset.xml"}},
+			Entry: []atomfeed.Entry{download("second.gpkg", "")},
+		},
+		{
+			ID:    "https://test.com/path/dataset-archive.xml",
+			Title: "dataset title",
+			Entry: []atomfeed.Entry{download("archived.gpkg", "")},
+		},
+	}
+}
+
+func TestGetDCATCatalog(t *testing.T) {
+	catalog, err := getDCATCatalog(getDCATTestFeeds("https://creativecommons.org/publicdomain/zero/1.0/deed.nl"))
+	if err != nil {
+		t.Fatalf("getDCATCatalog() error = %v", err)
+	}
+	if catalog.ID != "https://test.com/path/" || catalog.Title != `service "title"` || catalog.Homepage != "https://test.com/path/index.html" {
+		t.Errorf("getDCATCatalog() = %+v, want the service feed as catalog", catalog)
+	}
+	if len(catalog.Datasets) != 1 {
+		t.Fatalf("getDCATCatalog() datasets = %+v, want 1", catalog.Datasets)
+	}
+
+	dataset := catalog.Datasets[0]
+	if dataset.ID != "https://test.com/path/dataset" || dataset.LandingPage != "https://test.com/path/dataset.html" || dataset.Identifier != "dataset-id" {
+		t.Errorf("getDCATCatalog() dataset = %+v", dataset)
+	}
+	if dataset.License != "https://creativecommons.org/publicdomain/zero/1.0/deed.nl" || dataset.Rights != "" {
+		t.Errorf("getDCATCatalog() license = %q, rights = %q, want the rights as license", dataset.License, dataset.Rights)
+	}
+	if dataset.BBox != "POLYGON((3 50, 7 50, 7 53, 3 53, 3 50))" {
+		t.Errorf("getDCATCatalog() bbox = %q", dataset.BBox)
+	}
+
+	var urls []string
+	for _, distribution := range dataset.Distributions {
+		urls = append(urls, distribution.URL)
+	}
+	if strings.Join(urls, " ") != "https://test.com/path/downloads/first.gpkg https://test.com/path/downloads/second.gpkg" {
+		t.Errorf("getDCATCatalog() distributions = %v, want the downloads of every page except the archive", urls)
+	}
+	first := dataset.Distributions[0]
+	if first.MediaType != "https://www.iana.org/assignments/media-types/application/geopackage+sqlite3" || first.ByteSize != "512" ||
+		first.BBox != "POLYGON((3 50, 7 50, 7 51, 3 51, 3 50))" {
+		t.Errorf("getDCATCatalog() distribution = %+v", first)
+	}
+}
+
+func TestRenderDCAT(t *testing.T) {
+	jsonLD, rdfXML, err := RenderDCAT(getDCATTestFeeds("no license"))
+	if err != nil {
+		t.Fatalf("RenderDCAT() error = %v", err)
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal([]byte(jsonLD), &document); err != nil {
+		t.Fatalf("RenderDCAT() JSON-LD is not valid: %v", err)
+	}
+	if document["@type"] != "dcat:Catalog" || document["@id"] != "https://test.com/path/" {
+		t.Errorf("RenderDCAT() JSON-LD = %s, want a catalog", jsonLD)
+	}
+	for _, want := range []string{
+		`"@language": "nl"`,
+		`"rdfs:label": "no license"`,
+		`"@type": "dcat:Distribution"`,
+		`"@type": "http://www.opengis.net/ont/geosparql#wktLiteral"`,
+	} {
+		if !strings.Contains(jsonLD, want) {
+			t.Errorf("RenderDCAT() JSON-LD = %s, want %s", jsonLD, want)
+		}
+	}
+	if strings.Contains(jsonLD, `"dct:license"`) {
+		t.Errorf("RenderDCAT() JSON-LD = %s, should not have a license for rights that are no URL", jsonLD)
+	}
+
+	if err := xml.Unmarshal([]byte(rdfXML), new(struct{})); err != nil {
+		t.Fatalf("RenderDCAT() RDF/XML is not valid: %v", err)
+	}
+	for _, want := range []string{
+		`xmlns:dcat="http://www.w3.org/ns/dcat#"`,
+		`<dcat:Catalog rdf:about="https://test.com/path/">`,
+		`<dct:title xml:lang="nl">service &#34;title&#34;</dct:title>`,
+		`<dcat:Dataset rdf:about="https://test.com/path/dataset">`,
+		`<dcat:downloadURL rdf:resource="https://test.com/path/downloads/second.gpkg"></dcat:downloadURL>`,
+		`<dcat:byteSize rdf:datatype="http://www.w3.org/2001/XMLSchema#nonNegativeInteger">512</dcat:byteSize>`,
+		`<rdfs:label>no license</rdfs:label>`,
+	} {
+		if !strings.Contains(rdfXML, want) {
+			t.Errorf("RenderDCAT() RDF/XML = %s, want %s", rdfXML, want)
+		}
+	}
+}
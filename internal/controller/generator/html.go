@@ -0,0 +1,233 @@
+package generator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+//go:embed feed.html.tmpl
+var feedHTMLTemplate string
+
+var feedTemplate = template.Must(template.New("feed").Parse(feedHTMLTemplate))
+
+// htmlLabels are the fixed texts of the HTML pages by language, English is used for other languages
+var htmlLabels = map[string]htmlPageLabels{
+	"nl": {
+		Up: "Naar het overzicht", Navigation: "Pagina's", Metadata: "Metadata", Links: "Links", Datasets: "Datasets",
+		Downloads: "Downloads", Download: "Download", Format: "Formaat", Size: "Grootte", BBox: "Omgrenzing",
+		Time: "Tijd", Updated: "Bijgewerkt", Categories: "Categorieën", Feed: "Bekijk de downloads",
+		Alternate: "Deze pagina als Atom feed", Rights: "Gebruiksvoorwaarden", Author: "Contact",
+		Rels: map[string]string{
+			"first": "Eerste pagina", "prev": "Vorige pagina", "next": "Volgende pagina", "last": "Laatste pagina",
+			"current": "Actuele downloads", "prev-archive": "Gearchiveerde downloads",
+		},
+	},
+	"en": {
+		Up: "Back to the overview", Navigation: "Pages", Metadata: "Metadata", Links: "Links", Datasets: "Datasets",
+		Downloads: "Downloads", Download: "Download", Format: "Format", Size: "Size", BBox: "Bounding box",
+		Time: "Time", Updated: "Updated", Categories: "Categories", Feed: "View the downloads",
+		Alternate: "This page as Atom feed", Rights: "Rights", Author: "Contact",
+		Rels: map[string]string{
+			"first": "First page", "prev": "Previous page", "next": "Next page", "last": "Last page",
+			"current": "Current downloads", "prev-archive": "Archived downloads",
+		},
+	},
+}
+
+// navigationRels are the rels of links between the pages of a dataset feed (RFC 5005), in the order they are shown
+var navigationRels = []string{"first", "prev", "next", "last", "current", "prev-archive"}
+
+type htmlPageLabels struct {
+	Up, Navigation, Metadata, Links, Datasets, Downloads, Download, Format, Size, BBox, Time, Updated, Categories, Feed, Alternate, Rights, Author string
+	// Rels are the texts of the navigation links by rel
+	Rels map[string]string
+}
+
+type htmlPage struct {
+	Lang          string
+	Labels        htmlPageLabels
+	Title         string
+	Subtitle      string
+	FeedHref      string
+	Up            *htmlLink
+	Navigation    []htmlLink
+	MetadataLinks []htmlLink
+	Links         []htmlLink
+	Entries       []htmlEntry
+	// Datasets is set on the page of the service feed, whose entries are the dataset feeds
+	Datasets bool
+	Rights   string
+	Updated  string
+	Author   atomfeed.Author
+}
+
+type htmlEntry struct {
+	Title   string
+	Summary string
+	Updated string
+	// Categories are the labels of the categories, such as coordinate reference systems, keywords and INSPIRE themes
+	Categories    []string
+	Feed          *htmlLink
+	MetadataLinks []htmlLink
+	Links         []htmlLink
+	Downloads     []htmlDownload
+}
+
+type htmlLink struct {
+	Href  string
+	Title string
+	Type  string
+	Rel   string
+}
+
+type htmlDownload struct {
+	htmlLink
+	Size string
+	BBox string
+	Time string
+}
+
+// RenderHTML renders a static HTML page of the feed, so browsers can show the feed without an XSLT stylesheet.
+// Links to the other feeds of the Atom are replaced by links to their HTML page, feedFileName is the file name of the feed itself.
+func RenderHTML(feed atomfeed.Feed, feedFileName string) (string, error) {
+	baseURL := strings.TrimSuffix(feed.ID, feedFileName)
+	toPage := func(link atomfeed.Link) htmlLink {
+		href := link.Href
+		if strings.HasPrefix(href, baseURL) && strings.HasSuffix(href, ".xml") {
+			href = pdoknlv3.GetHTMLFileName(strings.TrimPrefix(href, baseURL))
+		}
+		return htmlLink{Href: href, Title: unescapeQuotes(link.Title), Type: link.Type, Rel: link.Rel}
+	}
+
+	page := htmlPage{
+		Lang:     "en",
+		Title:    unescapeQuotes(feed.Title),
+		Subtitle: unescapeQuotes(feed.Subtitle),
+		FeedHref: feedFileName,
+		Rights:   feed.Rights,
+		Author:   feed.Author,
+		Datasets: feedFileName == "index.xml",
+	}
+	if feed.Lang != nil && *feed.Lang != "" {
+		page.Lang = *feed.Lang
+	}
+	labels, ok := htmlLabels[page.Lang]
+	if !ok {
+		labels = htmlLabels["en"]
+	}
+	page.Labels = labels
+	if feed.Updated != nil {
+		page.Updated = *feed.Updated
+	}
+
+	for _, rel := range navigationRels {
+		for _, link := range feed.Link {
+			if link.Rel == rel {
+				navigationLink := toPage(link)
+				navigationLink.Title = labels.Rels[rel]
+				page.Navigation = append(page.Navigation, navigationLink)
+			}
+		}
+	}
+	for _, link := range feed.Link {
+		switch link.Rel {
+		case "up":
+			up := toPage(link)
+			page.Up = &up
+		case "describedby":
+			page.MetadataLinks = append(page.MetadataLinks, getTitledLink(toPage(link)))
+		case "self", "search":
+		default:
+			if !slices.Contains(navigationRels, link.Rel) {
+				page.Links = append(page.Links, getTitledLink(toPage(link)))
+			}
+		}
+	}
+
+	for _, entry := range feed.Entry {
+		htmlEntry := htmlEntry{
+			Title:   unescapeQuotes(entry.Title),
+			Summary: unescapeQuotes(entry.Summary),
+		}
+		if entry.Content != "" {
+			htmlEntry.Summary = entry.Content
+		}
+		if entry.Updated != nil {
+			htmlEntry.Updated = *entry.Updated
+		}
+		for _, category := range entry.Category {
+			htmlEntry.Categories = append(htmlEntry.Categories, category.Label)
+		}
+		for _, link := range entry.Link {
+			switch {
+			case link.Rel == "describedby":
+				htmlEntry.MetadataLinks = append(htmlEntry.MetadataLinks, getTitledLink(toPage(link)))
+			case page.Datasets && link.Rel == "alternate" && link.Type == "application/atom+xml":
+				feedLink := toPage(link)
+				htmlEntry.Feed = &feedLink
+			case !page.Datasets && isDownloadRel(link.Rel):
+				download := htmlDownload{htmlLink: toPage(link), Size: formatSize(link.Length)}
+				if link.Bbox != nil {
+					download.BBox = *link.Bbox
+				}
+				if link.Time != nil {
+					download.Time = *link.Time
+				}
+				htmlEntry.Downloads = append(htmlEntry.Downloads, download)
+			default:
+				htmlEntry.Links = append(htmlEntry.Links, getTitledLink(toPage(link)))
+			}
+		}
+		page.Entries = append(page.Entries, htmlEntry)
+	}
+
+	var html bytes.Buffer
+	if err := feedTemplate.Execute(&html, page); err != nil {
+		return "", fmt.Errorf("could not render the html page of feed %s: %w", feed.ID, err)
+	}
+	return html.String(), nil
+}
+
+// isDownloadRel returns whether a link with the given rel is a download of the dataset,
+// "section" being the rel of each file of a dataset that is split into multiple files.
+func isDownloadRel(rel string) bool {
+	return rel == "alternate" || rel == "enclosure" || rel == "section"
+}
+
+// getTitledLink uses the href as title of links without one, so the text of every link is meaningful
+func getTitledLink(link htmlLink) htmlLink {
+	if link.Title == "" {
+		link.Title = link.Href
+	}
+	return link
+}
+
+// formatSize formats a length in bytes with a decimal unit, lengths that are not a number are left out
+func formatSize(length string) string {
+	size, err := strconv.ParseFloat(length, 64)
+	if err != nil || size < 0 {
+		return ""
+	}
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	unit := 0
+	for size >= 1000 && unit < len(units)-1 {
+		size /= 1000
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", size, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
+
+func unescapeQuotes(s string) string {
+	return strings.ReplaceAll(s, "\\\"", "\"")
+}
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func TestRenderHTML(t *testing.T) {
+	serviceFeed := atomfeed.Feed{
+		ID:       "https://test.com/path/index.xml",
+		Title:    `service \"title\"`,
+		Subtitle: "service subtitle",
+		Lang:     smoothutil.Pointer("nl"),
+		Link: []atomfeed.Link{
+			{Rel: "self", Href: "https://test.com/path/index.xml"},
+			{Rel: "describedby", Href: "https://metadata.test/service", Type: "text/html", Title: "service metadata"},
+		},
+		Rights:  "rights",
+		Updated: smoothutil.Pointer("2006-01-02T15:04:05Z"),
+		Author:  atomfeed.Author{Name: "author", Email: "author@test.com"},
+		Entry: []atomfeed.Entry{{
+			ID:       "https://test.com/path/dataset.xml",
+			Title:    "dataset title",
+			Summary:  "<dataset> summary",
+			Category: []atomfeed.Category{{Term: "https://www.opengis.net/def/crs/EPSG/0/28992", Label: "Amersfoort / RD New"}},
+			Link: []atomfeed.Link{
+				{Rel: "describedby", Href: "https://metadata.test/dataset", Type: "application/xml"},
+				{Rel: "alternate", Href: "https://test.com/path/dataset.xml", Type: "application/atom+xml", Title: "dataset title"},
+			},
+		}},
+	}
+	datasetFeed := atomfeed.Feed{
+		ID:    "https://test.com/path/dataset-2.xml",
+		Title: "dataset title",
+		Lang:  smoothutil.Pointer("en"),
+		Link: []atomfeed.Link{
+			{Rel: "up", Href: "https://test.com/path/index.xml", Type: "application/atom+xml"},
+			{Rel: "prev", Href: "https://test.com/path/dataset.xml", Type: "application/atom+xml"},
+			{Rel: "related", Href: "https://test.com/wfs", Type: "application/xml", Title: "WFS"},
+		},
+		Entry: []atomfeed.Entry{{
+			ID:    "https://test.com/path/entry.xml",
+			Title: "entry title",
+			Link: []atomfeed.Link{{
+				Rel:    "alternate",
+				Href:   "https://test.com/path/downloads/file.gpkg",
+				Type:   "application/geopackage+sqlite3",
+				Length: "1234567",
+				Title:  "file.gpkg",
+				Bbox:   smoothutil.Pointer("50.6 3.2 53.7 7.2"),
+				Time:   smoothutil.Pointer("2006-01-02T15:04:05Z"),
+			}, {
+				Rel:   "related",
+				Href:  "https://test.com/wms",
+				Type:  "application/xml",
+				Title: "WMS",
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		feed         atomfeed.Feed
+		feedFileName string
+		want         []string
+		wantNot      []string
+	}{
+		{
+			name:         "service_feed",
+			feed:         serviceFeed,
+			feedFileName: "index.xml",
+			want: []string{
+				`<html lang="nl">`,
+				`<h1>service &#34;title&#34;</h1>`,
+				`<link rel="alternate" type="application/atom+xml" href="index.xml"`,
+				`<a href="https://metadata.test/service" type="text/html">service metadata</a>`,
+				`<h2>Datasets</h2>`,
+				`<p>&lt;dataset&gt; summary</p>`,
+				`<dt>Categorieën</dt>`,
+				`<dd>Amersfoort / RD New</dd>`,
+				`<dd><a href="https://metadata.test/dataset" type="application/xml">https://metadata.test/dataset</a></dd>`,
+				`<a href="dataset.html">Bekijk de downloads: dataset title</a>`,
+				`Gebruiksvoorwaarden: rights`,
+				`(<a href="mailto:author@test.com">author@test.com</a>)`,
+			},
+			wantNot: []string{"<table>", "Back to the overview"},
+		},
+		{
+			name:         "dataset_feed",
+			feed:         datasetFeed,
+			feedFileName: "dataset-2.xml",
+			want: []string{
+				`<html lang="en">`,
+				`<a href="index.html">Back to the overview</a>`,
+				`<h2>Links</h2>`,
+				`<li><a href="https://test.com/wfs" type="application/xml">WFS</a></li>`,
+				`<caption>Downloads</caption>`,
+				`<td><a href="https://test.com/path/downloads/file.gpkg" type="application/geopackage&#43;sqlite3">file.gpkg</a></td>`,
+				`<td>1.2 MB</td>`,
+				`<td>50.6 3.2 53.7 7.2</td>`,
+				`<dt>Links</dt>`,
+				`<dd><a href="https://test.com/wms" type="application/xml">WMS</a></dd>`,
+				`<a href="dataset.html" rel="prev">Previous page</a>`,
+				`<a href="dataset-2.xml" type="application/atom+xml">This page as Atom feed</a>`,
+			},
+			wantNot: []string{`<td><a href="https://test.com/wms"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := RenderHTML(tt.feed, tt.feedFileName)
+			if err != nil {
+				t.Fatalf("RenderHTML() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("RenderHTML() = %s, want %s", html, want)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(html, wantNot) {
+					t.Errorf("RenderHTML() = %s, should not contain %s", html, wantNot)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[string]string{"": "", "unknown": "", "512": "512 B", "1000": "1.0 kB", "1234567": "1.2 MB", "5000000000": "5.0 GB"}
+	for length, want := range tests {
+		if got := formatSize(length); got != want {
+			t.Errorf("formatSize(%q) = %q, want %q", length, got, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"sync"
+)
+
+// LinkDataCache remembers the type and length of the downloads of every Atom, so they are not requested on every render.
+// The links of an Atom are kept until they are forgotten, on a refresh or deletion of the Atom, or are no longer rendered.
+// A nil cache remembers nothing.
+type LinkDataCache struct {
+	mu    sync.Mutex
+	atoms map[string]map[string]linkData
+}
+
+type linkData struct {
+	Type   string
+	Length string
+}
+
+// NewLinkDataCache returns an empty cache
+func NewLinkDataCache() *LinkDataCache {
+	return &LinkDataCache{atoms: make(map[string]map[string]linkData)}
+}
+
+// get returns the link data of the Atom by data URL
+func (c *LinkDataCache) get(atomKey string) map[string]linkData {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.atoms[atomKey]
+}
+
+// set replaces the link data of the Atom, so the links it no longer renders are dropped
+func (c *LinkDataCache) set(atomKey string, links map[string]linkData) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.atoms[atomKey] = links
+}
+
+// Forget removes the link data of the Atom, so the type and length of its downloads are requested again
+func (c *LinkDataCache) Forget(atomKey string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.atoms, atomKey)
+}
@@ -2,19 +2,22 @@ package generator
 
 import (
 	"errors"
+	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/cbroglie/mustache"
 	atomfeed "github.com/pdok/atom-generator/feeds"
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
 	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
 	smoothoperatormodel "github.com/pdok/smooth-operator/model"
 	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func MapAtomV3ToAtomGeneratorConfig(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo) (atomGeneratorConfig atomfeed.Feeds, err error) {
+// MapAtomV3ToAtomGeneratorConfig maps the Atom to the config of the atom-generator, which requests the downloads from the blobEndpoint
+func MapAtomV3ToAtomGeneratorConfig(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, blobEndpoint string) (atomGeneratorConfig atomfeed.Feeds, err error) {
 	if ownerInfo.Spec.Atom == nil {
 		return atomGeneratorConfig, errors.New("ownerInfo has no Atom information defined")
 	}
@@ -22,12 +25,16 @@ func MapAtomV3ToAtomGeneratorConfig(atom pdoknlv3.Atom, ownerInfo smoothoperator
 	selfLink := getSelfLink(atom)
 	links := []atomfeed.Link{selfLink}
 	if atom.Spec.Service.ServiceMetadataLinks != nil {
-		err = addMetadataLinks(*atom.Spec.Service.ServiceMetadataLinks, ownerInfo, &links, "NGR pagina voor deze download service", false)
+		err = addMetadataLinks(*atom.Spec.Service.ServiceMetadataLinks, ownerInfo, pdoknlv3.GetServiceHrefVariables(&atom), &links, "NGR pagina voor deze download service", pdoknlv3.MetadataLinkContextService)
 		if err != nil {
 			return atomfeed.Feeds{}, err
 		}
 	}
 
+	if license := atom.Spec.Service.GetLicense(nil); license != nil {
+		links = append(links, getLicenseLink(*license))
+	}
+
 	// TODO append custom links to links (requires mapping)
 	// links = append(links, atom.Spec.Service.Links...)
 
@@ -55,30 +62,50 @@ func MapAtomV3ToAtomGeneratorConfig(atom pdoknlv3.Atom, ownerInfo smoothoperator
 		Title:         escapeQuotes(atom.Spec.Service.Title),
 		Subtitle:      escapeQuotes(atom.Spec.Service.Subtitle),
 		// Index Feed Links
-		Link:   links,
-		Rights: atom.Spec.Service.Rights,
-		Author: getAuthor(ownerInfo.Spec.Atom.Author),
-		Entry:  entries,
+		Link:    links,
+		Rights:  atom.Spec.Service.GetRights(nil),
+		Updated: formatUpdated(getServiceUpdated(atom.Spec.Service)),
+		Author:  getAuthor(ownerInfo.Spec.Atom.Author),
+		Entry:   entries,
 	}
 	atomGeneratorConfig.Feeds = append(atomGeneratorConfig.Feeds, serviceFeed)
 
 	for _, datasetFeed := range atom.Spec.Service.DatasetFeeds {
-		datasetLinks, err := getDatasetLinks(atom, ownerInfo, datasetFeed)
-		if err != nil {
-			return atomfeed.Feeds{}, err
+		// Every page of the dataset feed is a separate feed
+		pages := datasetFeed.GetPages()
+		for i, pageEntries := range pages {
+			navigationLinks := getPagingLinks(atom, datasetFeed, i+1, len(pages))
+			if i == 0 && datasetFeed.Archive != nil {
+				navigationLinks = append(navigationLinks, getFeedLink(atom, "prev-archive", datasetFeed.GetArchiveFileName()))
+			}
+
+			datasetLinks, err := getDatasetLinks(atom, ownerInfo, datasetFeed, datasetFeed.GetPageFileName(i+1), navigationLinks)
+			if err != nil {
+				return atomfeed.Feeds{}, err
+			}
+			dsFeed := atomfeed.Feed{
+				ID:            atom.Spec.Service.BaseURL.JoinPath(datasetFeed.GetPageFileName(i + 1)).String(),
+				Title:         escapeQuotes(datasetFeed.Title),
+				Subtitle:      escapeQuotes(datasetFeed.Subtitle),
+				Lang:          &atom.Spec.Service.Lang,
+				Link:          datasetLinks,
+				Rights:        atom.Spec.Service.GetRights(&datasetFeed),
+				Updated:       formatUpdated(getDatasetFeedUpdated(datasetFeed)),
+				XMLStylesheet: xmlStylesheet,
+				Author:        getAuthor(datasetFeed.Author),
+				Entry:         getDatasetEntries(atom, datasetFeed, pageEntries, blobEndpoint),
+			}
+			atomGeneratorConfig.Feeds = append(atomGeneratorConfig.Feeds, dsFeed)
 		}
-		dsFeed := atomfeed.Feed{
-			ID:            atom.Spec.Service.BaseURL.JoinPath(datasetFeed.TechnicalName + ".xml").String(),
-			Title:         escapeQuotes(datasetFeed.Title),
-			Subtitle:      escapeQuotes(datasetFeed.Subtitle),
-			Lang:          &atom.Spec.Service.Lang,
-			Link:          datasetLinks,
-			Rights:        atom.Spec.Service.Rights,
-			XMLStylesheet: xmlStylesheet,
-			Author:        getAuthor(datasetFeed.Author),
-			Entry:         getDatasetEntries(atom, datasetFeed),
+
+		if datasetFeed.Archive != nil {
+			archiveFeed, err := getArchiveFeed(atom, ownerInfo, datasetFeed, blobEndpoint)
+			if err != nil {
+				return atomfeed.Feeds{}, err
+			}
+			archiveFeed.XMLStylesheet = xmlStylesheet
+			atomGeneratorConfig.Feeds = append(atomGeneratorConfig.Feeds, archiveFeed)
 		}
-		atomGeneratorConfig.Feeds = append(atomGeneratorConfig.Feeds, dsFeed)
 	}
 	return atomGeneratorConfig, err
 }
@@ -89,7 +116,7 @@ func getServiceEntries(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo)
 		id := atom.Spec.Service.BaseURL.JoinPath(datasetFeed.TechnicalName + ".xml").String()
 		var links []atomfeed.Link
 		if datasetFeed.DatasetMetadataLinks != nil {
-			err := addMetadataLinks(*datasetFeed.DatasetMetadataLinks, ownerInfo, &links, "", true)
+			err := addMetadataLinks(*datasetFeed.DatasetMetadataLinks, ownerInfo, pdoknlv3.GetDatasetHrefVariables(&atom, datasetFeed), &links, "", pdoknlv3.MetadataLinkContextDatasetEntry)
 			if err != nil {
 				return nil, err
 			}
@@ -101,6 +128,9 @@ func getServiceEntries(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo)
 			Title: escapeQuotes(datasetFeed.Title),
 		}
 		links = append(links, alternateLink)
+		if datasetFeed.License != nil {
+			links = append(links, getLicenseLink(*datasetFeed.License))
+		}
 		datasetEntry := atomfeed.Entry{
 			ID:                                id,
 			Title:                             escapeQuotes(datasetFeed.Title),
@@ -108,13 +138,14 @@ func getServiceEntries(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo)
 			SpatialDatasetIdentifierNamespace: datasetFeed.SpatialDatasetIdentifierNamespace,
 			Link:                              links,
 			Summary:                           escapeQuotes(datasetFeed.Subtitle),
+			Updated:                           formatUpdated(getDatasetFeedUpdated(datasetFeed)),
 			Category:                          []atomfeed.Category{},
 		}
 
 		// TODO willen we hier een verbetering doorvoeren dat het altijd de max polygon van alle entries maakt?
 		// Take the polygon bbox of the first entry, assuming all are equal
 		if len(datasetFeed.Entries) > 0 {
-			datasetEntry.Polygon = datasetFeed.Entries[0].Polygon.BBox.ToPolygon()
+			datasetEntry.Polygon = getGeoRSSPolygon(datasetFeed.Entries[0].Polygon, datasetFeed.Entries[0].SRS)
 		}
 
 		// Collect all categories
@@ -139,6 +170,89 @@ func getCategory(srs pdoknlv3.SRS) atomfeed.Category {
 	}
 }
 
+// getGeoRSSPolygon returns the polygon as georss polygon, which has to be in WGS84.
+// A georss polygon has no holes, so of a polygon with holes the exterior ring is used and of a multipolygon the bbox.
+// When rendered by the operator these are replaced by the georss:where of GetEntryWheres.
+// Coordinates in an SRS that cannot be transformed are used as is.
+func getGeoRSSPolygon(polygon pdoknlv3.Polygon, srs pdoknlv3.SRS) string {
+	if polygon.Geometry != nil {
+		if geometry, err := pdoknlv3.ParseGeometry(*polygon.Geometry); err == nil && len(geometry) == 1 {
+			return ringToGeoRSSPolygon(geometry[0][0], srs)
+		}
+	}
+
+	bbox := polygon.GetBBox()
+	if wgs84BBox, ok := transformBBoxToWGS84(bbox, srs); ok {
+		return wgs84BBox.ToPolygon()
+	}
+	return bbox.ToPolygon()
+}
+
+// ringToGeoRSSPolygon returns the "lat lon" pairs of the ring
+func ringToGeoRSSPolygon(ring pdoknlv3.Ring, srs pdoknlv3.SRS) string {
+	transform, ok := getTransformToWGS84(srs)
+	if !ok {
+		transform = func(x, y float64) (float64, float64) { return x, y }
+	}
+
+	coordinates := make([]string, 0, 2*len(ring))
+	for _, position := range ring {
+		lon, lat := transform(position[0], position[1])
+		if ok {
+			coordinates = append(coordinates, formatDegrees(lat), formatDegrees(lon))
+		} else {
+			coordinates = append(coordinates, strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64))
+		}
+	}
+	return strings.Join(coordinates, " ")
+}
+
+// getServiceUpdated returns when the service was last updated, which is the newest updated of the dataset feeds when not set
+func getServiceUpdated(service pdoknlv3.Service) *metav1.Time {
+	if service.Updated != nil {
+		return service.Updated
+	}
+	var updated *metav1.Time
+	for _, datasetFeed := range service.DatasetFeeds {
+		if feedUpdated := getDatasetFeedUpdated(datasetFeed); feedUpdated != nil && (updated == nil || updated.Before(feedUpdated)) {
+			updated = feedUpdated
+		}
+	}
+	return updated
+}
+
+// getDatasetFeedUpdated returns when the dataset feed was last updated, which is the newest updated of its current entries
+// when not set. A feed of which all entries are archived falls back to the newest archived entry.
+func getDatasetFeedUpdated(datasetFeed pdoknlv3.DatasetFeed) *metav1.Time {
+	if datasetFeed.Updated != nil {
+		return datasetFeed.Updated
+	}
+	if updated := getNewestUpdated(datasetFeed.GetCurrentEntries()); updated != nil {
+		return updated
+	}
+	return getNewestUpdated(datasetFeed.GetArchivedEntries())
+}
+
+// getNewestUpdated returns the newest updated of the entries, nil without entries
+func getNewestUpdated(entries []pdoknlv3.Entry) *metav1.Time {
+	var updated *metav1.Time
+	for i := range entries {
+		if updated == nil || updated.Before(&entries[i].Updated) {
+			updated = &entries[i].Updated
+		}
+	}
+	return updated
+}
+
+// formatUpdated returns the time as the UTC timestamp of an updated element
+func formatUpdated(updated *metav1.Time) *string {
+	if updated == nil {
+		return nil
+	}
+	formatted := updated.In(time.FixedZone("UTC", 0)).Format(time.RFC3339)
+	return &formatted
+}
+
 func getAuthor(author smoothoperatormodel.Author) atomfeed.Author {
 	return atomfeed.Author{
 		Name:  author.Name,
@@ -155,64 +269,74 @@ func getSelfLink(atom pdoknlv3.Atom) atomfeed.Link {
 	}
 }
 
-func replaceMustacheTemplate(hrefTemplate string, identifier string) (string, error) {
-	templateVariable := map[string]string{"identifier": identifier}
-	return mustache.Render(hrefTemplate, templateVariable)
-}
+// addMetadataLinks adds a link for every template of the metadata links that applies to the context, the hrefs are rendered
+// with the variables of the context. The html template is titled with htmlTitle, as its title depends on the context.
+func addMetadataLinks(metadataLinks pdoknlv3.MetadataLink, ownerInfo smoothoperatorv1.OwnerInfo, variables map[string]string, links *[]atomfeed.Link, htmlTitle string, context pdoknlv3.MetadataLinkContext) error {
+	for _, name := range metadataLinks.Templates {
+		template, err := pdoknlv3.GetMetadataLinkTemplate(&ownerInfo, name)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			return fmt.Errorf("metadata link template %s is not defined in OwnerInfo %s", name, ownerInfo.Name)
+		}
+		if !template.AppliesTo(context) {
+			continue
+		}
 
-func addMetadataLinks(metadataLinks pdoknlv3.MetadataLink, ownerInfo smoothoperatorv1.OwnerInfo, links *[]atomfeed.Link, htmlTitle string, onlyCSW bool) error {
-	for _, template := range metadataLinks.Templates {
-		if template == "csw" {
-			href, err := replaceMustacheTemplate(ownerInfo.Spec.MetadataUrls.CSW.HrefTemplate, metadataLinks.MetadataIdentifier)
-			if err != nil {
-				return err
-			}
-			link := atomfeed.Link{
-				Rel:  "describedby",
-				Href: href,
-				Type: "application/xml",
-			}
-			*links = append(*links, link)
-			if onlyCSW {
-				return nil
-			}
+		href, err := pdoknlv3.RenderHrefTemplate(template.HrefTemplate, variables)
+		if err != nil {
+			return err
 		}
-		if template == "opensearch" {
-			href, err := replaceMustacheTemplate(ownerInfo.Spec.MetadataUrls.OpenSearch.HrefTemplate, metadataLinks.MetadataIdentifier)
-			if err != nil {
-				return err
-			}
-			link := atomfeed.Link{
-				Rel:   "search",
-				Href:  href,
-				Title: "Open Search document voor INSPIRE Download service PDOK", // TODO move to ownerRef?
-				Type:  "application/opensearchdescription+xml",
-			}
-			*links = append(*links, link)
+		link := atomfeed.Link{
+			Rel:  template.Rel,
+			Href: href,
+			Type: template.Type,
 		}
-		if template == "html" {
-			href, err := replaceMustacheTemplate(ownerInfo.Spec.MetadataUrls.HTML.HrefTemplate, metadataLinks.MetadataIdentifier)
-			if err != nil {
-				return err
-			}
-			link := atomfeed.Link{
-				Rel:   "describedby",
-				Href:  href,
-				Type:  "text/html",
-				Title: htmlTitle,
-			}
-			*links = append(*links, link)
+		if template.Title != nil {
+			link.Title = *template.Title
+		} else if name == "html" {
+			link.Title = htmlTitle
 		}
+		*links = append(*links, link)
 	}
 
 	return nil
 }
 
-func getDatasetLinks(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, datasetFeed pdoknlv3.DatasetFeed) ([]atomfeed.Link, error) {
+// getArchiveFeed returns the feed with the archived entries of the dataset feed, it links back to the current feed (RFC 5005)
+func getArchiveFeed(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, datasetFeed pdoknlv3.DatasetFeed, blobEndpoint string) (atomfeed.Feed, error) {
+	navigationLinks := []atomfeed.Link{getFeedLink(atom, "current", datasetFeed.GetPageFileName(1))}
+	datasetLinks, err := getDatasetLinks(atom, ownerInfo, datasetFeed, datasetFeed.GetArchiveFileName(), navigationLinks)
+	if err != nil {
+		return atomfeed.Feed{}, err
+	}
+
+	title := datasetFeed.Title
+	if datasetFeed.Archive.Title != nil {
+		title = *datasetFeed.Archive.Title
+	}
+
+	archivedEntries := datasetFeed.GetArchivedEntries()
+	// The updated of the dataset feed does not apply to the archive, its updated is that of the newest archived entry
+	return atomfeed.Feed{
+		ID:       atom.Spec.Service.BaseURL.JoinPath(datasetFeed.GetArchiveFileName()).String(),
+		Title:    escapeQuotes(title),
+		Subtitle: escapeQuotes(datasetFeed.Subtitle),
+		Lang:     &atom.Spec.Service.Lang,
+		Link:     datasetLinks,
+		Rights:   atom.Spec.Service.GetRights(&datasetFeed),
+		Updated:  formatUpdated(getNewestUpdated(archivedEntries)),
+		Author:   getAuthor(datasetFeed.Author),
+		Entry:    getDatasetEntries(atom, datasetFeed, archivedEntries, blobEndpoint),
+	}, nil
+}
+
+func getDatasetLinks(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, datasetFeed pdoknlv3.DatasetFeed, fileName string, navigationLinks []atomfeed.Link) ([]atomfeed.Link, error) {
 
 	selfLink := atomfeed.Link{
 		Rel:  "self",
-		Href: atom.Spec.Service.BaseURL.JoinPath(datasetFeed.TechnicalName + ".xml").String(),
+		Href: atom.Spec.Service.BaseURL.JoinPath(fileName).String(),
 	}
 	upLink := atomfeed.Link{
 		Rel:   "up",
@@ -225,18 +349,23 @@ func getDatasetLinks(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, d
 		selfLink,
 		upLink,
 	}
+	links = append(links, navigationLinks...)
 
 	if datasetFeed.DatasetMetadataLinks != nil {
-		err := addMetadataLinks(*datasetFeed.DatasetMetadataLinks, ownerInfo, &links, "NGR pagina voor deze dataset", false)
+		err := addMetadataLinks(*datasetFeed.DatasetMetadataLinks, ownerInfo, pdoknlv3.GetDatasetHrefVariables(&atom, datasetFeed), &links, "NGR pagina voor deze dataset", pdoknlv3.MetadataLinkContextDatasetFeed)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	for _, link := range datasetFeed.Links {
+		href, err := pdoknlv3.RenderHrefTemplate(pdoknlv3.GetHrefTemplate(link.Href), pdoknlv3.GetDatasetHrefVariables(&atom, datasetFeed))
+		if err != nil {
+			return nil, err
+		}
 		linkDescribedbyLink := atomfeed.Link{
 			Rel:      link.Rel,
-			Href:     link.Href.String(),
+			Href:     href,
 			Type:     link.Type,
 			Hreflang: link.Hreflang,
 		}
@@ -246,19 +375,65 @@ func getDatasetLinks(atom pdoknlv3.Atom, ownerInfo smoothoperatorv1.OwnerInfo, d
 		links = append(links, linkDescribedbyLink)
 	}
 
+	for _, serviceLink := range datasetFeed.ServiceLinks {
+		links = append(links, getServiceLink(serviceLink, false))
+	}
+
+	if license := atom.Spec.Service.GetLicense(&datasetFeed); license != nil {
+		links = append(links, getLicenseLink(*license))
+	}
+
 	return links, nil
 }
 
-func getDatasetEntries(atom pdoknlv3.Atom, datasetFeed pdoknlv3.DatasetFeed) []atomfeed.Entry {
+func getLicenseLink(license pdoknlv3.License) atomfeed.Link {
+	return atomfeed.Link{
+		Rel:   "license",
+		Href:  license.URI.String(),
+		Type:  "text/html",
+		Title: escapeQuotes(license.Label),
+	}
+}
+
+// getPagingLinks returns the links between the pages of a paged feed (RFC 5005), a feed with a single page has none
+func getPagingLinks(atom pdoknlv3.Atom, datasetFeed pdoknlv3.DatasetFeed, page int, pageCount int) []atomfeed.Link {
+	if pageCount <= 1 {
+		return nil
+	}
+
+	pageLink := func(rel string, page int) atomfeed.Link {
+		return getFeedLink(atom, rel, datasetFeed.GetPageFileName(page))
+	}
+
+	links := []atomfeed.Link{pageLink("first", 1)}
+	if page > 1 {
+		links = append(links, pageLink("prev", page-1))
+	}
+	if page < pageCount {
+		links = append(links, pageLink("next", page+1))
+	}
+	return append(links, pageLink("last", pageCount))
+}
+
+// getFeedLink returns a link to another feed of the Atom
+func getFeedLink(atom pdoknlv3.Atom, rel string, fileName string) atomfeed.Link {
+	return atomfeed.Link{
+		Rel:  rel,
+		Href: atom.Spec.Service.BaseURL.JoinPath(fileName).String(),
+		Type: "application/atom+xml",
+	}
+}
+
+func getDatasetEntries(atom pdoknlv3.Atom, datasetFeed pdoknlv3.DatasetFeed, pageEntries []pdoknlv3.Entry, blobEndpoint string) []atomfeed.Entry {
 	var entries []atomfeed.Entry
-	for _, entry := range datasetFeed.Entries {
+	for _, entry := range pageEntries {
 
 		datasetEntry := atomfeed.Entry{
 			ID:       atom.Spec.Service.BaseURL.JoinPath(entry.TechnicalName + ".xml").String(),
 			Link:     []atomfeed.Link{},
-			Rights:   atom.Spec.Service.Rights,
+			Rights:   atom.Spec.Service.GetRights(&datasetFeed),
 			Category: []atomfeed.Category{getCategory(entry.SRS)},
-			Polygon:  entry.Polygon.BBox.ToPolygon(),
+			Polygon:  getGeoRSSPolygon(entry.Polygon, entry.SRS),
 		}
 
 		if entry.Title != nil {
@@ -271,18 +446,25 @@ func getDatasetEntries(atom pdoknlv3.Atom, datasetFeed pdoknlv3.DatasetFeed) []a
 			datasetEntry.Content = *entry.Content
 		}
 
-		updated := entry.Updated.In(time.FixedZone("UTC", 0)).Format(time.RFC3339)
-		datasetEntry.Updated = &updated
+		datasetEntry.Updated = formatUpdated(&entry.Updated)
 
-		emptyRelCount := getEmptyRelCount(entry)
 		for _, downloadLink := range entry.DownloadLinks {
 			link := atomfeed.Link{
-				Rel:   getDownloadLinkRel(downloadLink, emptyRelCount),
+				Rel:   entry.GetDownloadLinkRel(downloadLink),
 				Href:  getDownloadLinkHref(downloadLink, atom),
-				Data:  getDownloadLinkData(downloadLink),
+				Data:  getDownloadLinkData(downloadLink, blobEndpoint),
 				Title: getDownloadLinkTitle(datasetFeed, entry, downloadLink),
 			}
 
+			if downloadLink.Type != nil {
+				link.Type = *downloadLink.Type
+			}
+			if downloadLink.Length != nil {
+				link.Length = strconv.FormatInt(*downloadLink.Length, 10)
+			}
+			if entry.Version != nil {
+				link.Version = entry.Version
+			}
 			if downloadLink.Time != nil {
 				link.Time = downloadLink.Time
 			}
@@ -293,43 +475,27 @@ func getDatasetEntries(atom pdoknlv3.Atom, datasetFeed pdoknlv3.DatasetFeed) []a
 
 			datasetEntry.Link = append(datasetEntry.Link, link)
 		}
-		entries = append(entries, datasetEntry)
-	}
 
-	return entries
-}
-
-func getEmptyRelCount(entry pdoknlv3.Entry) (count int) {
-	for _, downloadLink := range entry.DownloadLinks {
-		// TODO zou een "" niet eigenlijk een error moeten zijn?
-		// maar dat hoort mogelijk meer bij de admission
-		if downloadLink.Rel == nil || *downloadLink.Rel == "" {
-			count++
+		for _, serviceLink := range entry.ServiceLinks {
+			datasetEntry.Link = append(datasetEntry.Link, getServiceLink(serviceLink, true))
 		}
+		entries = append(entries, datasetEntry)
 	}
-	return
-}
 
-func getDownloadLinkRel(downloadLink pdoknlv3.DownloadLink, emptyRelCount int) (rel string) {
-	switch {
-	case downloadLink.Rel != nil && *downloadLink.Rel != "":
-		rel = *downloadLink.Rel
-	case emptyRelCount > 1:
-		rel = "section"
-	default:
-		rel = "alternate"
-	}
-	return
+	return entries
 }
 
 func getDownloadLinkHref(downloadLink pdoknlv3.DownloadLink, atom pdoknlv3.Atom) string {
 	return atom.Spec.Service.BaseURL.JoinPath("downloads", downloadLink.GetBlobName()).String()
 }
 
-// Using internal url, atom generator uses this url to determine content-length and
-// content-type of the download and convert it into external url
-func getDownloadLinkData(downloadLink pdoknlv3.DownloadLink) *string {
-	data := pdoknlv3.GetBlobEndpoint() + "/" + downloadLink.Data
+// getDownloadLinkData returns the URL in the blob storage at the blobEndpoint the atom-generator uses to determine
+// the type and length of the download, there is no need for it when both are known
+func getDownloadLinkData(downloadLink pdoknlv3.DownloadLink, blobEndpoint string) *string {
+	if downloadLink.Type != nil && downloadLink.Length != nil {
+		return nil
+	}
+	data := blobEndpoint + "/" + downloadLink.Data
 	return &data
 }
 
@@ -343,6 +509,64 @@ func getDownloadLinkTitle(datasetFeed pdoknlv3.DatasetFeed, entry pdoknlv3.Entry
 	return
 }
 
+// getServiceLink returns a link to the service, or a link that requests the data of the layer directly when forEntry is set.
+// The links point to the service itself, not to the downloads of the Atom. They are related links, also on entries, as
+// alternate links of an entry may not repeat the type and hreflang of its download links (RFC 4287 4.2.7.2).
+func getServiceLink(serviceLink pdoknlv3.ServiceLink, forEntry bool) atomfeed.Link {
+	href := *serviceLink.Endpoint.URL
+	query := href.Query()
+	link := atomfeed.Link{Rel: "related"}
+
+	var name string
+	switch serviceLink.Protocol {
+	case "wfs":
+		name = "WFS"
+		query.Set("service", "WFS")
+		if forEntry {
+			query.Set("version", "2.0.0")
+			query.Set("request", "GetFeature")
+			query.Set("typeNames", serviceLink.Layer)
+			link.Type = "application/gml+xml"
+		} else {
+			query.Set("request", "GetCapabilities")
+			link.Type = "application/xml"
+		}
+	case "wcs":
+		name = "WCS"
+		query.Set("service", "WCS")
+		if forEntry {
+			query.Set("version", "2.0.1")
+			query.Set("request", "GetCoverage")
+			query.Set("coverageId", serviceLink.Layer)
+			query.Set("format", "image/tiff")
+			link.Type = "image/tiff"
+		} else {
+			query.Set("request", "GetCapabilities")
+			link.Type = "application/xml"
+		}
+	case "ogcapi-features":
+		name = "OGC API Features"
+		if forEntry {
+			href = *href.JoinPath("collections", serviceLink.Layer, "items")
+			query.Set("f", "json")
+			link.Type = "application/geo+json"
+		} else {
+			href = *href.JoinPath("collections", serviceLink.Layer)
+			query.Set("f", "json")
+			link.Type = "application/json"
+		}
+	}
+	href.RawQuery = query.Encode()
+	link.Href = href.String()
+
+	if serviceLink.Title != nil {
+		link.Title = escapeQuotes(*serviceLink.Title)
+	} else {
+		link.Title = escapeQuotes(name + " " + serviceLink.Layer)
+	}
+	return link
+}
+
 func escapeQuotes(s string) string {
 	return strings.ReplaceAll(s, "\"", "\\\"")
 }
@@ -1,12 +1,17 @@
 package generator
 
 import (
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	atomfeed "github.com/pdok/atom-generator/feeds"
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
 	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestMapAtomV3ToAtomGeneratorConfig(t *testing.T) {
@@ -33,7 +38,7 @@ func TestMapAtomV3ToAtomGeneratorConfig(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAtomGeneratorConfig, err := MapAtomV3ToAtomGeneratorConfig(tt.args.atom, tt.args.ownerInfo)
+			gotAtomGeneratorConfig, err := MapAtomV3ToAtomGeneratorConfig(tt.args.atom, tt.args.ownerInfo, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("MapAtomV3ToAtomGeneratorConfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -44,3 +49,204 @@ func TestMapAtomV3ToAtomGeneratorConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMapAtomV3ToAtomGeneratorConfigUpdated(t *testing.T) {
+	baseURL, _ := url.Parse("https://test.com/path/")
+	srsURI, _ := url.Parse("https://www.opengis.net/def/crs/EPSG/0/28992")
+	srs := pdoknlv3.SRS{URI: smoothoperatormodel.URL{URL: srsURI}, Name: "Amersfoort / RD New"}
+	entry := func(year int, archived bool) pdoknlv3.Entry {
+		return pdoknlv3.Entry{Updated: testTime(year), Archived: archived, SRS: srs}
+	}
+
+	atom := pdoknlv3.Atom{Spec: pdoknlv3.AtomSpec{Service: pdoknlv3.Service{
+		BaseURL: smoothoperatormodel.URL{URL: baseURL},
+		DatasetFeeds: []pdoknlv3.DatasetFeed{
+			{
+				TechnicalName: "feed-1",
+				Entries:       []pdoknlv3.Entry{entry(2006, false), entry(2008, false), entry(2009, true)},
+				Archive:       &pdoknlv3.Archive{},
+			},
+			{
+				TechnicalName: "feed-2",
+				Entries:       []pdoknlv3.Entry{entry(2010, false)},
+				Updated:       smoothutil.Pointer(testTime(2005)),
+			},
+		},
+	}}}
+	ownerInfo := smoothoperatorv1.OwnerInfo{Spec: smoothoperatorv1.OwnerInfoSpec{Atom: &smoothoperatorv1.Atom{}}}
+
+	feeds, err := MapAtomV3ToAtomGeneratorConfig(atom, ownerInfo, "")
+	if err != nil {
+		t.Fatalf("MapAtomV3ToAtomGeneratorConfig() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, feed := range feeds.Feeds {
+		got[feed.ID] = valueOrEmpty(feed.Updated)
+	}
+	for _, entry := range feeds.Feeds[0].Entry {
+		got["entry "+entry.ID] = valueOrEmpty(entry.Updated)
+	}
+	want := map[string]string{
+		"https://test.com/path/index.xml":          "2008-01-02T15:04:05Z",
+		"entry https://test.com/path/feed-1.xml":   "2008-01-02T15:04:05Z",
+		"entry https://test.com/path/feed-2.xml":   "2005-01-02T15:04:05Z",
+		"https://test.com/path/feed-1.xml":         "2008-01-02T15:04:05Z",
+		"https://test.com/path/feed-1-archive.xml": "2009-01-02T15:04:05Z",
+		"https://test.com/path/feed-2.xml":         "2005-01-02T15:04:05Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MapAtomV3ToAtomGeneratorConfig() updated = %v, want %v", got, want)
+	}
+}
+
+func TestGetDatasetFeedUpdated(t *testing.T) {
+	tests := []struct {
+		name        string
+		datasetFeed pdoknlv3.DatasetFeed
+		want        *metav1.Time
+	}{
+		{
+			name:        "newest_entry",
+			datasetFeed: pdoknlv3.DatasetFeed{Entries: []pdoknlv3.Entry{{Updated: testTime(2006)}, {Updated: testTime(2007)}}},
+			want:        smoothutil.Pointer(testTime(2007)),
+		},
+		{
+			name: "explicit",
+			datasetFeed: pdoknlv3.DatasetFeed{
+				Entries: []pdoknlv3.Entry{{Updated: testTime(2007)}},
+				Updated: smoothutil.Pointer(testTime(2005)),
+			},
+			want: smoothutil.Pointer(testTime(2005)),
+		},
+		{
+			name: "without_archived_entries",
+			datasetFeed: pdoknlv3.DatasetFeed{
+				Entries: []pdoknlv3.Entry{{Updated: testTime(2006)}, {Updated: testTime(2007), Archived: true}},
+				Archive: &pdoknlv3.Archive{},
+			},
+			want: smoothutil.Pointer(testTime(2006)),
+		},
+		{
+			name: "all_entries_archived",
+			datasetFeed: pdoknlv3.DatasetFeed{
+				Entries: []pdoknlv3.Entry{{Updated: testTime(2006), Archived: true}, {Updated: testTime(2007), Archived: true}},
+				Archive: &pdoknlv3.Archive{},
+			},
+			want: smoothutil.Pointer(testTime(2007)),
+		},
+		{
+			name: "without_entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDatasetFeedUpdated(tt.datasetFeed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDatasetFeedUpdated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServiceUpdated(t *testing.T) {
+	datasetFeeds := []pdoknlv3.DatasetFeed{
+		{Entries: []pdoknlv3.Entry{{Updated: testTime(2006)}}},
+		{Entries: []pdoknlv3.Entry{{Updated: testTime(2008)}}, Updated: smoothutil.Pointer(testTime(2007))},
+	}
+	tests := []struct {
+		name    string
+		service pdoknlv3.Service
+		want    *metav1.Time
+	}{
+		{
+			name:    "newest_dataset_feed",
+			service: pdoknlv3.Service{DatasetFeeds: datasetFeeds},
+			want:    smoothutil.Pointer(testTime(2007)),
+		},
+		{
+			name:    "explicit",
+			service: pdoknlv3.Service{DatasetFeeds: datasetFeeds, Updated: smoothutil.Pointer(testTime(2005))},
+			want:    smoothutil.Pointer(testTime(2005)),
+		},
+		{
+			name:    "without_dataset_feeds",
+			service: pdoknlv3.Service{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getServiceUpdated(tt.service); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getServiceUpdated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testTime returns a time in the given year, in a zone other than UTC to test the formatting
+func testTime(year int) metav1.Time {
+	return metav1.NewTime(time.Date(year, 1, 2, 16, 4, 5, 0, time.FixedZone("CET", 3600)))
+}
+
+func TestAddMetadataLinks(t *testing.T) {
+	ownerInfo := smoothoperatorv1.OwnerInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "owner",
+			Annotations: map[string]string{pdoknlv3.MetadataLinkTemplatesAnnotation: `[
+				{"name": "iso", "rel": "describedby", "type": "application/xml", "title": "ISO 19139", "hrefTemplate": "https://catalogue.test/{{lang}}/{{identifier}}.xml", "contexts": ["datasetEntry", "datasetFeed"]},
+				{"name": "geonetwork", "rel": "alternate", "type": "application/json", "hrefTemplate": "https://geonetwork.test/api/records/{{identifier}}", "contexts": ["datasetFeed"]},
+				{"name": "unused", "type": "xml", "hrefTemplate": "{{identifier"}
+			]`},
+		},
+		Spec: smoothoperatorv1.OwnerInfoSpec{MetadataUrls: &smoothoperatorv1.MetadataUrls{
+			CSW:  &smoothoperatorv1.MetadataURL{HrefTemplate: "https://csw.test?id={{identifier}}"},
+			HTML: &smoothoperatorv1.MetadataURL{HrefTemplate: "https://html.test/{{identifier}}"},
+		}},
+	}
+	metadataLinks := pdoknlv3.MetadataLink{MetadataIdentifier: "id", Templates: []string{"html", "csw", "iso", "geonetwork"}}
+
+	tests := []struct {
+		name      string
+		ownerInfo smoothoperatorv1.OwnerInfo
+		context   pdoknlv3.MetadataLinkContext
+		want      []atomfeed.Link
+		wantErr   bool
+	}{
+		{
+			name:      "dataset_entry",
+			ownerInfo: ownerInfo,
+			context:   pdoknlv3.MetadataLinkContextDatasetEntry,
+			want: []atomfeed.Link{
+				{Rel: "describedby", Href: "https://csw.test?id=id", Type: "application/xml"},
+				{Rel: "describedby", Href: "https://catalogue.test/nl/id.xml", Type: "application/xml", Title: "ISO 19139"},
+			},
+		},
+		{
+			name:      "dataset_feed",
+			ownerInfo: ownerInfo,
+			context:   pdoknlv3.MetadataLinkContextDatasetFeed,
+			want: []atomfeed.Link{
+				{Rel: "describedby", Href: "https://html.test/id", Type: "text/html", Title: "html title"},
+				{Rel: "describedby", Href: "https://csw.test?id=id", Type: "application/xml"},
+				{Rel: "describedby", Href: "https://catalogue.test/nl/id.xml", Type: "application/xml", Title: "ISO 19139"},
+				{Rel: "alternate", Href: "https://geonetwork.test/api/records/id", Type: "application/json"},
+			},
+		},
+		{
+			name:      "undefined_template",
+			ownerInfo: smoothoperatorv1.OwnerInfo{Spec: ownerInfo.Spec},
+			context:   pdoknlv3.MetadataLinkContextDatasetFeed,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var links []atomfeed.Link
+			err := addMetadataLinks(metadataLinks, tt.ownerInfo, map[string]string{pdoknlv3.HrefVariableIdentifier: "id", pdoknlv3.HrefVariableLang: "nl"}, &links, "html title", tt.context)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addMetadataLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(links, tt.want) {
+				t.Errorf("addMetadataLinks() = %v, want %v", links, tt.want)
+			}
+		})
+	}
+}
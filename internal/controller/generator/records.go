@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+const (
+	recordsContentType          = "application/json"
+	crs84                       = "http://www.opengis.net/def/crs/OGC/1.3/CRS84"
+	recordCoreConformance       = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-core"
+	crawlableCatalogConformance = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/crawlable-catalog"
+)
+
+// recordsCatalog is the service feed as crawlable catalog of OGC API Records, the dataset feeds are its items
+type recordsCatalog struct {
+	ID          string           `json:"id"`
+	Type        string           `json:"type"`
+	ItemType    string           `json:"itemType"`
+	ConformsTo  []string         `json:"conformsTo"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Language    *recordsLanguage `json:"language,omitempty"`
+	Updated     string           `json:"updated,omitempty"`
+	Rights      string           `json:"rights,omitempty"`
+	Contacts    []recordsContact `json:"contacts,omitempty"`
+	Extent      *recordsExtent   `json:"extent,omitempty"`
+	Links       []recordsLink    `json:"links"`
+}
+
+// record is a dataset feed, or a page of it, as OGC API Records record with the downloads as enclosures
+type record struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"`
+	ConformsTo []string         `json:"conformsTo"`
+	Time       any              `json:"time"`
+	Geometry   *recordsGeometry `json:"geometry"`
+	Properties recordProperties `json:"properties"`
+	Links      []recordsLink    `json:"links"`
+}
+
+type recordProperties struct {
+	Type        string           `json:"type"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Language    *recordsLanguage `json:"language,omitempty"`
+	Updated     string           `json:"updated,omitempty"`
+	Rights      string           `json:"rights,omitempty"`
+	Contacts    []recordsContact `json:"contacts,omitempty"`
+	Formats     []recordsFormat  `json:"formats,omitempty"`
+}
+
+type recordsLanguage struct {
+	Code string `json:"code"`
+}
+
+type recordsContact struct {
+	Name   string         `json:"name,omitempty"`
+	Emails []recordsEmail `json:"emails,omitempty"`
+	Roles  []string       `json:"roles"`
+}
+
+type recordsEmail struct {
+	Value string `json:"value"`
+}
+
+type recordsFormat struct {
+	MediaType string `json:"mediaType"`
+}
+
+type recordsExtent struct {
+	Spatial recordsSpatialExtent `json:"spatial"`
+}
+
+type recordsSpatialExtent struct {
+	BBox [][4]float64 `json:"bbox"`
+	CRS  string       `json:"crs"`
+}
+
+type recordsGeometry struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// recordsLink is an OGC link, enclosures also have the CRS of the download
+type recordsLink struct {
+	Href     string  `json:"href"`
+	Rel      string  `json:"rel"`
+	Type     string  `json:"type,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Hreflang *string `json:"hreflang,omitempty"`
+	Length   *int64  `json:"length,omitempty"`
+	CRS      string  `json:"crs,omitempty"`
+}
+
+// RenderRecords renders the feed as OGC API Records JSON: the service feed as catalog and a dataset feed as record.
+// Links to feeds of the service point to their JSON instead.
+func RenderRecords(feed atomfeed.Feed, feedFileName string) (string, error) {
+	baseURL := strings.TrimSuffix(feed.ID, feedFileName)
+	toJSON := func(link atomfeed.Link) recordsLink {
+		href, linkType := link.Href, link.Type
+		if strings.HasPrefix(href, baseURL) && strings.HasSuffix(href, ".xml") {
+			href, linkType = pdoknlv3.GetJSONFileName(href), recordsContentType
+		}
+		return recordsLink{Href: href, Rel: link.Rel, Type: linkType, Title: unescapeQuotes(link.Title), Hreflang: link.Hreflang}
+	}
+
+	links := []recordsLink{
+		{Href: pdoknlv3.GetJSONFileName(feed.ID), Rel: "self", Type: recordsContentType, Title: unescapeQuotes(feed.Title)},
+		{Href: feed.ID, Rel: "alternate", Type: "application/atom+xml", Title: unescapeQuotes(feed.Title)},
+		{Href: pdoknlv3.GetHTMLFileName(feed.ID), Rel: "alternate", Type: "text/html", Title: unescapeQuotes(feed.Title)},
+	}
+	for _, link := range feed.Link {
+		switch link.Rel {
+		case "self":
+		case "up":
+			up := toJSON(link)
+			up.Rel = "collection"
+			links = append(links, up)
+		default:
+			links = append(links, toJSON(link))
+		}
+	}
+	var language *recordsLanguage
+	if feed.Lang != nil && *feed.Lang != "" {
+		language = &recordsLanguage{Code: *feed.Lang}
+	}
+	contacts := getRecordsContacts(feed.Author)
+
+	var document any
+	if feedFileName == "index.xml" {
+		catalog := recordsCatalog{
+			ID:          pdoknlv3.GetJSONFileName(feed.ID),
+			Type:        "Catalog",
+			ItemType:    "record",
+			ConformsTo:  []string{crawlableCatalogConformance},
+			Title:       unescapeQuotes(feed.Title),
+			Description: unescapeQuotes(feed.Subtitle),
+			Language:    language,
+			Updated:     valueOrEmpty(feed.Updated),
+			Rights:      feed.Rights,
+			Contacts:    contacts,
+			Links:       links,
+		}
+		var polygons []string
+		for _, entry := range feed.Entry {
+			polygons = append(polygons, entry.Polygon)
+			for _, link := range entry.Link {
+				if link.Rel == "alternate" && link.Type == "application/atom+xml" {
+					item := toJSON(link)
+					item.Rel = "item"
+					catalog.Links = append(catalog.Links, item)
+				}
+			}
+		}
+		if envelope, ok := getGeoRSSEnvelope(polygons...); ok {
+			catalog.Extent = &recordsExtent{Spatial: recordsSpatialExtent{BBox: [][4]float64{envelope}, CRS: crs84}}
+		}
+		document = catalog
+	} else {
+		record := record{
+			ID:         pdoknlv3.GetJSONFileName(feed.ID),
+			Type:       "Feature",
+			ConformsTo: []string{recordCoreConformance},
+			Properties: recordProperties{
+				Type:        "dataset",
+				Title:       unescapeQuotes(feed.Title),
+				Description: unescapeQuotes(feed.Subtitle),
+				Language:    language,
+				Updated:     valueOrEmpty(feed.Updated),
+				Rights:      feed.Rights,
+				Contacts:    contacts,
+			},
+			Links: links,
+		}
+		var polygons []string
+		for _, entry := range feed.Entry {
+			polygons = append(polygons, entry.Polygon)
+			var crs string
+			if len(entry.Category) > 0 {
+				crs = entry.Category[0].Term
+			}
+			for _, link := range entry.Link {
+				recordLink := toJSON(link)
+				if strings.HasPrefix(link.Href, baseURL+"downloads/") {
+					recordLink.Rel, recordLink.CRS = "enclosure", crs
+					if length, err := strconv.ParseInt(link.Length, 10, 64); err == nil {
+						recordLink.Length = &length
+					}
+					record.Properties.Formats = appendFormat(record.Properties.Formats, link.Type)
+				}
+				record.Links = append(record.Links, recordLink)
+			}
+		}
+		if envelope, ok := getGeoRSSEnvelope(polygons...); ok {
+			record.Geometry = &recordsGeometry{Type: "Polygon", Coordinates: [][][2]float64{{
+				{envelope[0], envelope[1]}, {envelope[2], envelope[1]}, {envelope[2], envelope[3]}, {envelope[0], envelope[3]}, {envelope[0], envelope[1]},
+			}}}
+		}
+		document = record
+	}
+
+	rendered, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not render the records of feed %s: %w", feed.ID, err)
+	}
+	return string(rendered) + "\n", nil
+}
+
+func getRecordsContacts(author atomfeed.Author) []recordsContact {
+	if author.Name == "" && author.Email == "" {
+		return nil
+	}
+	contact := recordsContact{Name: author.Name, Roles: []string{"author"}}
+	if author.Email != "" {
+		contact.Emails = []recordsEmail{{Value: author.Email}}
+	}
+	return []recordsContact{contact}
+}
+
+func appendFormat(formats []recordsFormat, mediaType string) []recordsFormat {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if mediaType = strings.TrimSpace(mediaType); mediaType == "" {
+		return formats
+	}
+	for _, format := range formats {
+		if format.MediaType == mediaType {
+			return formats
+		}
+	}
+	return append(formats, recordsFormat{MediaType: mediaType})
+}
+
+// getGeoRSSEnvelope returns the envelope of georss polygons of "lat lon" pairs as minLon, minLat, maxLon, maxLat
+func getGeoRSSEnvelope(polygons ...string) ([4]float64, bool) {
+	envelope := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	found := false
+	for _, polygon := range polygons {
+		coordinates := strings.Fields(polygon)
+		if len(coordinates)%2 != 0 {
+			continue
+		}
+		for i := 0; i < len(coordinates); i += 2 {
+			lat, latErr := strconv.ParseFloat(coordinates[i], 64)
+			lon, lonErr := strconv.ParseFloat(coordinates[i+1], 64)
+			if latErr != nil || lonErr != nil {
+				continue
+			}
+			envelope = [4]float64{min(envelope[0], lon), min(envelope[1], lat), max(envelope[2], lon), max(envelope[3], lat)}
+			found = true
+		}
+	}
+	return envelope, found
+}
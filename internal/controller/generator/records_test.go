@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func TestRenderRecords(t *testing.T) {
+	serviceFeed := atomfeed.Feed{
+		ID:       "https://test.com/path/index.xml",
+		Title:    `service \"title\"`,
+		Subtitle: "service subtitle",
+		Lang:     smoothutil.Pointer("nl"),
+		Link: []atomfeed.Link{
+			{Rel: "self", Href: "https://test.com/path/index.xml"},
+			{Rel: "describedby", Href: "https://metadata.test/service", Type: "text/html"},
+		},
+		Rights: "rights",
+		Author: atomfeed.Author{Name: "author", Email: "author@test.com"},
+		Entry: []atomfeed.Entry{
+			{
+				Title:   "dataset title",
+				Polygon: "50 3 50 7 53 7 53 3 50 3",
+				Link:    []atomfeed.Link{{Rel: "alternate", Href: "https://test.com/path/dataset.xml", Type: "application/atom+xml", Title: "dataset title"}},
+			},
+			{
+				Title:   "other title",
+				Polygon: "49 2 49 4 51 4 51 2 49 2",
+				Link:    []atomfeed.Link{{Rel: "alternate", Href: "https://test.com/path/other.xml", Type: "application/atom+xml"}},
+			},
+		},
+	}
+	datasetFeed := atomfeed.Feed{
+		ID:    "https://test.com/path/dataset-2.xml",
+		Title: "dataset title",
+		Link: []atomfeed.Link{
+			{Rel: "up", Href: "https://test.com/path/index.xml", Type: "application/atom+xml"},
+			{Rel: "prev", Href: "https://test.com/path/dataset.xml", Type: "application/atom+xml"},
+		},
+		Entry: []atomfeed.Entry{{
+			Polygon:  "50 3 50 7 51 7 51 3 50 3",
+			Category: []atomfeed.Category{{Term: "https://www.opengis.net/def/crs/EPSG/0/28992", Label: "Amersfoort / RD New"}},
+			Link: []atomfeed.Link{
+				{Rel: "alternate", Href: "https://test.com/path/downloads/file.gpkg", Type: "application/geopackage+sqlite3", Length: "512", Title: "file.gpkg"},
+				{Rel: "related", Href: "https://test.com/wfs", Type: "application/xml", Title: "WFS"},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		feed         atomfeed.Feed
+		feedFileName string
+		want         []string
+	}{
+		{
+			name:         "service_feed",
+			feed:         serviceFeed,
+			feedFileName: "index.xml",
+			want: []string{
+				`"type": "Catalog"`,
+				`"title": "service \"title\""`,
+				`"href": "https://test.com/path/index.json",
+      "rel": "self"`,
+				`"href": "https://test.com/path/dataset.json",
+      "rel": "item",
+      "type": "application/json"`,
+				`"href": "https://test.com/path/other.json",
+      "rel": "item"`,
+				`"bbox": [
+        [
+          2,
+          49,
+          7,
+          53
+        ]
+      ],
+      "crs": "http://www.opengis.net/def/crs/OGC/1.3/CRS84"`,
+			},
+		},
+		{
+			name:         "dataset_feed",
+			feed:         datasetFeed,
+			feedFileName: "dataset-2.xml",
+			want: []string{
+				`"type": "Feature"`,
+				`"type": "Polygon"`,
+				`"mediaType": "application/geopackage+sqlite3"`,
+				`"href": "https://test.com/path/index.json",
+      "rel": "collection"`,
+				`"href": "https://test.com/path/dataset.json",
+      "rel": "prev"`,
+				`"href": "https://test.com/path/downloads/file.gpkg",
+      "rel": "enclosure",
+      "type": "application/geopackage+sqlite3",
+      "title": "file.gpkg",
+      "length": 512,
+      "crs": "https://www.opengis.net/def/crs/EPSG/0/28992"`,
+				`"href": "https://test.com/wfs",
+      "rel": "related"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := RenderRecords(tt.feed, tt.feedFileName)
+			if err != nil {
+				t.Fatalf("RenderRecords() error = %v", err)
+			}
+			if !json.Valid([]byte(records)) {
+				t.Fatalf("RenderRecords() = %s, which is not valid JSON", records)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(records, want) {
+					t.Errorf("RenderRecords() = %s, want %s", records, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetGeoRSSEnvelope(t *testing.T) {
+	envelope, ok := getGeoRSSEnvelope("50 3 50 7 53 7 53 3 50 3", "49.5 2.5 49.5 4 51 4 51 2.5 49.5 2.5", "invalid")
+	if !ok || envelope != [4]float64{2.5, 49.5, 7, 53} {
+		t.Errorf("getGeoRSSEnvelope() = %v, %v", envelope, ok)
+	}
+	if _, ok := getGeoRSSEnvelope(""); ok {
+		t.Errorf("getGeoRSSEnvelope() of no polygon should not have an envelope")
+	}
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+// MaxConfigMapSize is the size of the rendered feeds that fits in a ConfigMap, which can hold at most 1 MiB.
+// Some room is left for the metadata.
+const MaxConfigMapSize = 1000 * 1024
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// RenderOptions is what is rendered next to the generator config
+type RenderOptions struct {
+	// Records renders the OGC API Records JSON of every feed
+	Records bool
+
+	// Categories of the feeds by feed ID
+	Categories map[string][]Category
+
+	// Wheres replace the georss:polygon of entries by feed ID and entry ID
+	Wheres map[string]map[string]Where
+
+	// LinkData remembers the type and length of downloads between renders, they are requested every render when nil
+	LinkData *LinkDataCache
+
+	// LinkDataKey is the key of the Atom in the link data cache
+	LinkDataKey string
+
+	// SkipLinkData leaves the type and length of downloads that are not known yet out instead of requesting them
+	SkipLinkData bool
+}
+
+// GetRenderOptions returns the options to render the feeds of the Atom with
+func GetRenderOptions(atom pdoknlv3.Atom) RenderOptions {
+	return RenderOptions{
+		Records:     atom.Spec.Service.Records,
+		Categories:  GetFeedCategories(atom),
+		Wheres:      GetEntryWheres(atom),
+		LinkDataKey: GetLinkDataKey(atom),
+	}
+}
+
+// GetLinkDataKey returns the key of the Atom in the link data cache
+func GetLinkDataKey(atom pdoknlv3.Atom) string {
+	return atom.Namespace + "/" + atom.Name
+}
+
+// RenderFeeds renders the feeds of the generator config to XML and to an HTML page per feed, keyed by file name.
+// The DCAT-AP documents of the feeds are rendered next to them, and with records the OGC API Records JSON of every feed.
+// The type and length of download links that are not known yet are requested from the blob storage with the given client.
+// The rendered feeds are not limited in size, see GetRenderedSize for whether they fit in a ConfigMap.
+func RenderFeeds(atomGeneratorConfig atomfeed.Feeds, options RenderOptions, client *http.Client) (map[string]string, error) {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	// The atom-generator would request these itself, but panics when the request fails
+	if err := resolveLinkData(&atomGeneratorConfig, options, client); err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string]string)
+	feeds := atomfeed.ProcessFeeds(atomGeneratorConfig)
+	for _, feed := range feeds {
+		if err := feed.Valid(); err != nil {
+			return nil, fmt.Errorf("feed %s is not valid: %w", feed.ID, err)
+		}
+		fileName, err := feed.GetFileName()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the file name of feed %s: %w", feed.ID, err)
+		}
+		if _, exists := rendered[fileName]; exists {
+			return nil, fmt.Errorf("multiple feeds use the file name %s", fileName)
+		}
+
+		htmlFileName := pdoknlv3.GetHTMLFileName(fileName)
+		if rendered[htmlFileName], err = RenderHTML(feed, fileName); err != nil {
+			return nil, err
+		}
+		feedXML, err := generateATOM(feed, options.Categories[feed.ID], options.Wheres[feed.ID])
+		if err != nil {
+			return nil, fmt.Errorf("could not render feed %s: %w", feed.ID, err)
+		}
+		rendered[fileName] = string(feedXML)
+
+		if options.Records {
+			jsonFileName := pdoknlv3.GetJSONFileName(fileName)
+			if rendered[jsonFileName], err = RenderRecords(feed, fileName); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	jsonLD, rdfXML, err := RenderDCAT(feeds)
+	if err != nil {
+		return nil, err
+	}
+	rendered[pdoknlv3.DCATJSONLDFileName], rendered[pdoknlv3.DCATRDFFileName] = jsonLD, rdfXML
+	return rendered, nil
+}
+
+// GetRenderedSize returns the size of the rendered feeds in a ConfigMap
+func GetRenderedSize(rendered map[string]string) (size int) {
+	for fileName, content := range rendered {
+		size += len(fileName) + len(content)
+	}
+	return size
+}
+
+// resolveLinkData fills in the type and length of links with data from a HEAD request on the data URL,
+// unless the link data cache of the options has them. Only the links that are still rendered are kept in the cache.
+func resolveLinkData(atomGeneratorConfig *atomfeed.Feeds, options RenderOptions, client *http.Client) error {
+	cached := options.LinkData.get(options.LinkDataKey)
+	resolved := make(map[string]linkData)
+	for i := range atomGeneratorConfig.Feeds {
+		for j := range atomGeneratorConfig.Feeds[i].Entry {
+			for k := range atomGeneratorConfig.Feeds[i].Entry[j].Link {
+				link := &atomGeneratorConfig.Feeds[i].Entry[j].Link[k]
+				if link.Data == nil {
+					continue
+				}
+				if options.SkipLinkData {
+					link.Data = nil
+					continue
+				}
+
+				data, ok := resolved[*link.Data]
+				if !ok {
+					data, ok = cached[*link.Data]
+				}
+				if !ok {
+					resp, err := client.Head(*link.Data)
+					if err != nil {
+						return fmt.Errorf("could not request download %s: %w", *link.Data, err)
+					}
+					_ = resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						return fmt.Errorf("download %s is not available: %s", *link.Data, resp.Status)
+					}
+					data = linkData{Type: resp.Header.Get("Content-Type"), Length: resp.Header.Get("Content-Length")}
+				}
+				resolved[*link.Data] = data
+
+				if link.Length == "" {
+					link.Length = data.Length
+				}
+				if link.Type == "" {
+					link.Type = data.Type
+				}
+				link.Data = nil
+			}
+		}
+	}
+	if !options.SkipLinkData {
+		options.LinkData.set(options.LinkDataKey, resolved)
+	}
+	return nil
+}
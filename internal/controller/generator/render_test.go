@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func getTestFeeds(data string, link atomfeed.Link) atomfeed.Feeds {
+	link.Href = "https://test.com/path/downloads/file.ext"
+	link.Data = &data
+	return atomfeed.Feeds{Feeds: []atomfeed.Feed{{
+		ID:      "https://test.com/path/index.xml",
+		Title:   "title",
+		Rights:  "rights",
+		Updated: smoothutil.Pointer("2006-01-02T15:04:05Z"),
+		Author:  atomfeed.Author{Name: "author", Email: "author@test.com"},
+		Entry: []atomfeed.Entry{{
+			ID:      "https://test.com/path/entry",
+			Updated: smoothutil.Pointer("2006-01-02T15:04:05Z"),
+			Link:    []atomfeed.Link{link},
+		}},
+	}}}
+}
+
+func TestRenderFeeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/container/file.ext" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Length", "512")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		data     string
+		link     atomfeed.Link
+		wantLink string
+		wantErr  string
+	}{
+		{
+			name:     "type_and_length_from_blob",
+			data:     server.URL + "/container/file.ext",
+			wantLink: `type="application/zip" hreflang="en" length="512"`,
+		},
+		{
+			name:     "known_type_is_kept",
+			data:     server.URL + "/container/file.ext",
+			link:     atomfeed.Link{Type: "application/x-custom"},
+			wantLink: `type="application/x-custom" hreflang="en" length="512"`,
+		},
+		{
+			name:    "missing_download",
+			data:    server.URL + "/container/missing.ext",
+			wantErr: "404 Not Found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderFeeds(getTestFeeds(tt.data, tt.link), RenderOptions{}, server.Client())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RenderFeeds() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderFeeds() error = %v", err)
+			}
+			feed, ok := rendered["index.xml"]
+			for _, fileName := range []string{"index.html", pdoknlv3.DCATJSONLDFileName, pdoknlv3.DCATRDFFileName} {
+				if _, exists := rendered[fileName]; !exists {
+					ok = false
+				}
+			}
+			if !ok || len(rendered) != 4 {
+				t.Fatalf("RenderFeeds() rendered %v, want only index.xml, index.html and the DCAT documents", rendered)
+			}
+			if !strings.Contains(feed, tt.wantLink) {
+				t.Errorf("RenderFeeds() = %s, want link with %s", feed, tt.wantLink)
+			}
+			if strings.Contains(feed, "data=") {
+				t.Errorf("RenderFeeds() = %s, should not contain the data url", feed)
+			}
+		})
+	}
+}
+
+func TestRenderFeedsLinkDataCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Length", "512")
+	}))
+	defer server.Close()
+
+	data := server.URL + "/container/file.ext"
+	options := RenderOptions{LinkData: NewLinkDataCache(), LinkDataKey: "namespace/atom"}
+	for i := 0; i < 2; i++ {
+		rendered, err := RenderFeeds(getTestFeeds(data, atomfeed.Link{}), options, server.Client())
+		if err != nil {
+			t.Fatalf("RenderFeeds() error = %v", err)
+		}
+		if want := `type="application/zip" hreflang="en" length="512"`; !strings.Contains(rendered["index.xml"], want) {
+			t.Errorf("RenderFeeds() = %s, want link with %s", rendered["index.xml"], want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("RenderFeeds() requested the download %d times, want it to be requested once", requests)
+	}
+
+	options.LinkData.Forget(options.LinkDataKey)
+	if _, err := RenderFeeds(getTestFeeds(data, atomfeed.Link{}), options, server.Client()); err != nil {
+		t.Fatalf("RenderFeeds() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("RenderFeeds() requested the download %d times after Forget, want it to be requested again", requests)
+	}
+
+	other := server.URL + "/container/other.ext"
+	if _, err := RenderFeeds(getTestFeeds(other, atomfeed.Link{}), options, server.Client()); err != nil {
+		t.Fatalf("RenderFeeds() error = %v", err)
+	}
+	if links := options.LinkData.get(options.LinkDataKey); len(links) != 1 || links[other] == (linkData{}) {
+		t.Errorf("RenderFeeds() cached %v, want only the links that are still rendered", links)
+	}
+
+	rendered, err := RenderFeeds(getTestFeeds(data, atomfeed.Link{}), RenderOptions{SkipLinkData: true}, server.Client())
+	if err != nil {
+		t.Fatalf("RenderFeeds() error = %v", err)
+	}
+	if requests != 3 || strings.Contains(rendered["index.xml"], "length=") {
+		t.Errorf("RenderFeeds() = %s, should leave out the length without requesting the download", rendered["index.xml"])
+	}
+}
@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"math"
+	"strconv"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+)
+
+// GRS80 ellipsoid, used by ETRS89. The difference with WGS84 is negligible for georss purposes.
+const (
+	grs80A = 6378137.0
+	grs80F = 1 / 298.257222101
+)
+
+// Number of points per bbox side that are transformed, so curved edges in WGS84 are still covered by the resulting bbox
+const bboxDensification = 10
+
+// transformFunc transforms a coordinate to WGS84 longitude and latitude in degrees
+type transformFunc func(x, y float64) (lon, lat float64)
+
+// getTransformToWGS84 returns the transformation from the SRS to WGS84, if it is supported
+func getTransformToWGS84(srs pdoknlv3.SRS) (transformFunc, bool) {
+	code, ok := srs.GetEPSGCode()
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case code == 4326 || code == 4258:
+		return func(x, y float64) (float64, float64) { return x, y }, true
+	case code == 28992:
+		return rdNewToWGS84, true
+	case code == 3035:
+		return laeaEuropeToWGS84, true
+	case code == 3857:
+		return webMercatorToWGS84, true
+	case code >= 25828 && code <= 25838:
+		// ETRS89 / UTM zone 28N-38N
+		return utmToWGS84(code - 25800), true
+	case code >= 32628 && code <= 32638:
+		// WGS 84 / UTM zone 28N-38N
+		return utmToWGS84(code - 32600), true
+	}
+	return nil, false
+}
+
+// transformBBoxToWGS84 returns the bbox in WGS84 (x = longitude, y = latitude) that covers the given bbox
+func transformBBoxToWGS84(bbox smoothoperatormodel.BBox, srs pdoknlv3.SRS) (smoothoperatormodel.BBox, bool) {
+	transform, ok := getTransformToWGS84(srs)
+	if !ok {
+		return bbox, false
+	}
+
+	var coords [4]float64
+	for i, value := range []string{bbox.MinX, bbox.MinY, bbox.MaxX, bbox.MaxY} {
+		coord, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return bbox, false
+		}
+		coords[i] = coord
+	}
+	minX, minY, maxX, maxY := coords[0], coords[1], coords[2], coords[3]
+
+	minLon, minLat := math.Inf(1), math.Inf(1)
+	maxLon, maxLat := math.Inf(-1), math.Inf(-1)
+	for i := 0; i <= bboxDensification; i++ {
+		fraction := float64(i) / bboxDensification
+		x := minX + (maxX-minX)*fraction
+		y := minY + (maxY-minY)*fraction
+		for _, point := range [][2]float64{{x, minY}, {x, maxY}, {minX, y}, {maxX, y}} {
+			lon, lat := transform(point[0], point[1])
+			minLon, maxLon = math.Min(minLon, lon), math.Max(maxLon, lon)
+			minLat, maxLat = math.Min(minLat, lat), math.Max(maxLat, lat)
+		}
+	}
+
+	return smoothoperatormodel.BBox{
+		MinX: formatDegrees(minLon),
+		MinY: formatDegrees(minLat),
+		MaxX: formatDegrees(maxLon),
+		MaxY: formatDegrees(maxLat),
+	}, true
+}
+
+// formatDegrees rounds to 6 decimals, which is about 10 cm
+func formatDegrees(degrees float64) string {
+	return strconv.FormatFloat(math.Round(degrees*1e6)/1e6, 'f', -1, 64)
+}
+
+// rdNewToWGS84 uses the approximation of Schreutelaar and Van Hees for Amersfoort / RD New, accurate to about a meter
+func rdNewToWGS84(x, y float64) (float64, float64) {
+	dX := (x - 155000) * 1e-5
+	dY := (y - 463000) * 1e-5
+
+	// coefficients {p, q, K} for dX^p * dY^q
+	latCoefficients := [][3]float64{
+		{0, 1, 3235.65389}, {2, 0, -32.58297}, {0, 2, -0.24750}, {2, 1, -0.84978},
+		{0, 3, -0.06550}, {2, 2, -0.01709}, {1, 0, -0.00738}, {4, 0, 0.00530},
+		{2, 3, -0.00039}, {4, 1, 0.00033}, {1, 1, -0.00012},
+	}
+	lonCoefficients := [][3]float64{
+		{1, 0, 5260.52916}, {1, 1, 105.94684}, {1, 2, 2.45656}, {3, 0, -0.81885},
+		{1, 3, 0.05594}, {3, 1, -0.05607}, {0, 1, 0.01199}, {3, 2, -0.00256},
+		{1, 4, 0.00128}, {0, 2, 0.00022}, {2, 0, -0.00022}, {5, 0, 0.00026},
+	}
+
+	var lat, lon float64
+	for _, c := range latCoefficients {
+		lat += c[2] * math.Pow(dX, c[0]) * math.Pow(dY, c[1])
+	}
+	for _, c := range lonCoefficients {
+		lon += c[2] * math.Pow(dX, c[0]) * math.Pow(dY, c[1])
+	}
+
+	return 5.38720621 + lon/3600, 52.15517440 + lat/3600
+}
+
+// laeaEuropeToWGS84 is the inverse Lambert Azimuthal Equal Area projection (Snyder) for ETRS89-extended / LAEA Europe
+func laeaEuropeToWGS84(x, y float64) (float64, float64) {
+	const lat0, lon0 = 52 * math.Pi / 180, 10 * math.Pi / 180
+	const falseEasting, falseNorthing = 4321000.0, 3210000.0
+
+	e2 := grs80F * (2 - grs80F)
+	e := math.Sqrt(e2)
+	q := func(phi float64) float64 {
+		sinPhi := math.Sin(phi)
+		return (1 - e2) * (sinPhi/(1-e2*sinPhi*sinPhi) - 1/(2*e)*math.Log((1-e*sinPhi)/(1+e*sinPhi)))
+	}
+
+	qp := q(math.Pi / 2)
+	beta1 := math.Asin(q(lat0) / qp)
+	rq := grs80A * math.Sqrt(qp/2)
+	d := grs80A * math.Cos(lat0) / (math.Sqrt(1-e2*math.Sin(lat0)*math.Sin(lat0)) * rq * math.Cos(beta1))
+
+	dx, dy := x-falseEasting, y-falseNorthing
+	rho := math.Hypot(dx/d, d*dy)
+	if rho == 0 {
+		return lon0 * 180 / math.Pi, lat0 * 180 / math.Pi
+	}
+	c := 2 * math.Asin(rho/(2*rq))
+	beta := math.Asin(math.Cos(c)*math.Sin(beta1) + d*dy*math.Sin(c)*math.Cos(beta1)/rho)
+	lon := lon0 + math.Atan2(dx*math.Sin(c), d*rho*math.Cos(beta1)*math.Cos(c)-d*d*dy*math.Sin(beta1)*math.Sin(c))
+	lat := beta +
+		(e2/3+31*e2*e2/180+517*e2*e2*e2/5040)*math.Sin(2*beta) +
+		(23*e2*e2/360+251*e2*e2*e2/3780)*math.Sin(4*beta) +
+		(761*e2*e2*e2/45360)*math.Sin(6*beta)
+
+	return lon * 180 / math.Pi, lat * 180 / math.Pi
+}
+
+// utmToWGS84 returns the inverse Transverse Mercator projection (Snyder) for a northern UTM zone
+func utmToWGS84(zone int) transformFunc {
+	const k0, falseEasting = 0.9996, 500000.0
+	lon0 := float64(zone*6-183) * math.Pi / 180
+
+	return func(x, y float64) (float64, float64) {
+		e2 := grs80F * (2 - grs80F)
+		ep2 := e2 / (1 - e2)
+		e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+
+		mu := y / k0 / (grs80A * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+		phi1 := mu +
+			(3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+			(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+			(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+			(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+		sinPhi1, cosPhi1, tanPhi1 := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+		n1 := grs80A / math.Sqrt(1-e2*sinPhi1*sinPhi1)
+		t1 := tanPhi1 * tanPhi1
+		c1 := ep2 * cosPhi1 * cosPhi1
+		r1 := grs80A * (1 - e2) / math.Pow(1-e2*sinPhi1*sinPhi1, 1.5)
+		d := (x - falseEasting) / (n1 * k0)
+
+		lat := phi1 - (n1*tanPhi1/r1)*(d*d/2-
+			(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+			(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+		lon := lon0 + (d-
+			(1+2*t1+c1)*math.Pow(d, 3)/6+
+			(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120)/cosPhi1
+
+		return lon * 180 / math.Pi, lat * 180 / math.Pi
+	}
+}
+
+// webMercatorToWGS84 is the inverse of the spherical Pseudo-Mercator projection
+func webMercatorToWGS84(x, y float64) (float64, float64) {
+	return x / grs80A * 180 / math.Pi, math.Atan(math.Sinh(y/grs80A)) * 180 / math.Pi
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"math"
+	"net/url"
+	"testing"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+)
+
+func getTestSRS(uri string) pdoknlv3.SRS {
+	parsed, _ := url.Parse(uri)
+	return pdoknlv3.SRS{URI: smoothoperatormodel.URL{URL: parsed}}
+}
+
+func TestGetTransformToWGS84(t *testing.T) {
+	tests := []struct {
+		name    string
+		srs     string
+		x, y    float64
+		wantLon float64
+		wantLat float64
+		wantOk  bool
+	}{
+		{name: "rd_new_amersfoort", srs: "https://www.opengis.net/def/crs/EPSG/0/28992", x: 155000, y: 463000, wantLon: 5.38720621, wantLat: 52.15517440, wantOk: true},
+		{name: "rd_new_maastricht", srs: "http://www.opengis.net/def/crs/EPSG/0/28992", x: 176000, y: 317000, wantLon: 5.68533, wantLat: 50.84246, wantOk: true},
+		{name: "etrs89", srs: "http://www.opengis.net/def/crs/EPSG/0/4258", x: 5.1, y: 52.1, wantLon: 5.1, wantLat: 52.1, wantOk: true},
+		{name: "laea_origin", srs: "http://www.opengis.net/def/crs/EPSG/0/3035", x: 4321000, y: 3210000, wantLon: 10, wantLat: 52, wantOk: true},
+		{name: "laea_5e_52n", srs: "http://www.opengis.net/def/crs/EPSG/0/3035", x: 3977921.18, y: 3221773.63, wantLon: 5, wantLat: 52, wantOk: true},
+		{name: "utm_31n_central_meridian", srs: "http://www.opengis.net/def/crs/EPSG/0/25831", x: 500000, y: 0, wantLon: 3, wantLat: 0, wantOk: true},
+		{name: "utm_31n_5e_52n", srs: "http://www.opengis.net/def/crs/EPSG/0/25831", x: 637294.37, y: 5762926.81, wantLon: 5, wantLat: 52, wantOk: true},
+		{name: "utm_32n_52n", srs: "http://www.opengis.net/def/crs/EPSG/0/25832", x: 500000, y: 5761038.2, wantLon: 9, wantLat: 52, wantOk: true},
+		{name: "web_mercator", srs: "http://www.opengis.net/def/crs/EPSG/0/3857", x: 0, y: 0, wantLon: 0, wantLat: 0, wantOk: true},
+		{name: "unknown", srs: "https://srs/test", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transform, ok := getTransformToWGS84(getTestSRS(tt.srs))
+			if ok != tt.wantOk {
+				t.Fatalf("getTransformToWGS84() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			lon, lat := transform(tt.x, tt.y)
+			if math.Abs(lon-tt.wantLon) > 1e-4 || math.Abs(lat-tt.wantLat) > 1e-4 {
+				t.Errorf("transform() = %v %v, want %v %v", lon, lat, tt.wantLon, tt.wantLat)
+			}
+		})
+	}
+}
+
+func TestGetGeoRSSPolygon(t *testing.T) {
+	bbox := smoothoperatormodel.BBox{MinX: "155000", MinY: "463000", MaxX: "155000", MaxY: "463000"}
+
+	got := getGeoRSSPolygon(pdoknlv3.Polygon{BBox: bbox}, getTestSRS("https://www.opengis.net/def/crs/EPSG/0/28992"))
+	want := "52.155174 5.387206 52.155174 5.387206 52.155174 5.387206 52.155174 5.387206 52.155174 5.387206"
+	if got != want {
+		t.Errorf("getGeoRSSPolygon() = %v, want %v", got, want)
+	}
+
+	got = getGeoRSSPolygon(pdoknlv3.Polygon{BBox: bbox}, getTestSRS("https://srs/test"))
+	if got != bbox.ToPolygon() {
+		t.Errorf("getGeoRSSPolygon() = %v, want %v", got, bbox.ToPolygon())
+	}
+}
+
+func TestGetGeoRSSPolygonFromGeometry(t *testing.T) {
+	tests := []struct {
+		name     string
+		geometry string
+		srs      string
+		want     string
+	}{
+		{
+			name:     "WKT polygon",
+			geometry: "POLYGON ((5 52, 6 52, 6 53, 5 52))",
+			srs:      "https://www.opengis.net/def/crs/EPSG/0/4326",
+			want:     "52 5 52 6 53 6 52 5",
+		},
+		{
+			name:     "GeoJSON polygon in RD",
+			geometry: `{"type": "Polygon", "coordinates": [[[155000, 463000], [156000, 463000], [155000, 464000], [155000, 463000]]]}`,
+			srs:      "https://www.opengis.net/def/crs/EPSG/0/28992",
+			want:     "52.155174 5.387206 52.155173 5.401819 52.164162 5.387206 52.155174 5.387206",
+		},
+		{
+			name:     "polygon in unknown SRS",
+			geometry: "POLYGON ((1 2, 3 2, 3 4, 1 2))",
+			srs:      "https://srs/test",
+			want:     "2 1 2 3 4 3 2 1",
+		},
+		{
+			name:     "multipolygon uses the bbox",
+			geometry: "MULTIPOLYGON (((5 52, 6 52, 6 53, 5 52)), ((7 50, 8 50, 8 51, 7 50)))",
+			srs:      "https://www.opengis.net/def/crs/EPSG/0/4326",
+			want:     "50 5 50 8 53 8 53 5 50 5",
+		},
+		{
+			name:     "polygon with hole uses the exterior ring",
+			geometry: "POLYGON ((0 0, 10 0, 10 10, 0 0), (6 2, 8 6, 8 2, 6 2))",
+			srs:      "https://www.opengis.net/def/crs/EPSG/0/4326",
+			want:     "0 0 0 10 10 10 0 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getGeoRSSPolygon(pdoknlv3.Polygon{Geometry: &tt.geometry}, getTestSRS(tt.srs))
+			if got != tt.want {
+				t.Errorf("getGeoRSSPolygon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+)
+
+// GMLNamespace is the namespace of the GML in georss:where
+const GMLNamespace = "http://www.opengis.net/gml"
+
+// Where is the georss:where of an entry. The generator config only has georss:polygon, which cannot express
+// multipolygons and holes, so the GML of these geometries is added to the XML of the feed when it is rendered.
+type Where struct {
+	Polygon      *gmlPolygon      `xml:"gml:Polygon,omitempty"`
+	MultiSurface *gmlMultiSurface `xml:"gml:MultiSurface,omitempty"`
+}
+
+type gmlPolygon struct {
+	Exterior gmlRing   `xml:"gml:exterior"`
+	Interior []gmlRing `xml:"gml:interior"`
+}
+
+type gmlRing struct {
+	PosList string `xml:"gml:LinearRing>gml:posList"`
+}
+
+type gmlMultiSurface struct {
+	SurfaceMember []gmlPolygon `xml:"gml:surfaceMember>gml:Polygon"`
+}
+
+// GetEntryWheres returns the georss:where of the entries whose geometry is not a single polygon without holes, by feed ID and entry ID
+func GetEntryWheres(atom pdoknlv3.Atom) map[string]map[string]Where {
+	feedWheres := make(map[string]map[string]Where)
+	addWhere := func(feedFileName, entryID string, entry pdoknlv3.Entry) {
+		where, ok := getWhere(entry.Polygon, entry.SRS)
+		if !ok {
+			return
+		}
+		feedID := atom.Spec.Service.BaseURL.JoinPath(feedFileName).String()
+		if feedWheres[feedID] == nil {
+			feedWheres[feedID] = make(map[string]Where)
+		}
+		feedWheres[feedID][entryID] = where
+	}
+	getEntryID := func(technicalName string) string {
+		return atom.Spec.Service.BaseURL.JoinPath(technicalName + ".xml").String()
+	}
+
+	for _, datasetFeed := range atom.Spec.Service.DatasetFeeds {
+		// The entry of the dataset feed in the service feed has the polygon of the first entry
+		if len(datasetFeed.Entries) > 0 {
+			addWhere("index.xml", getEntryID(datasetFeed.TechnicalName), datasetFeed.Entries[0])
+		}
+		for i, pageEntries := range datasetFeed.GetPages() {
+			for _, entry := range pageEntries {
+				addWhere(datasetFeed.GetPageFileName(i+1), getEntryID(entry.TechnicalName), entry)
+			}
+		}
+		for _, entry := range datasetFeed.GetArchivedEntries() {
+			addWhere(datasetFeed.GetArchiveFileName(), getEntryID(entry.TechnicalName), entry)
+		}
+	}
+	return feedWheres
+}
+
+// getWhere returns the geometry of the polygon as GML in WGS84, if it is not a single polygon without holes
+func getWhere(polygon pdoknlv3.Polygon, srs pdoknlv3.SRS) (Where, bool) {
+	if polygon.Geometry == nil {
+		return Where{}, false
+	}
+	geometry, err := pdoknlv3.ParseGeometry(*polygon.Geometry)
+	if err != nil || geometry.IsSimplePolygon() {
+		return Where{}, false
+	}
+
+	polygons := make([]gmlPolygon, 0, len(geometry))
+	for _, rings := range geometry {
+		gml := gmlPolygon{Exterior: gmlRing{PosList: ringToGeoRSSPolygon(rings[0], srs)}}
+		for _, interior := range rings[1:] {
+			gml.Interior = append(gml.Interior, gmlRing{PosList: ringToGeoRSSPolygon(interior, srs)})
+		}
+		polygons = append(polygons, gml)
+	}
+	if len(polygons) == 1 {
+		return Where{Polygon: &polygons[0]}, true
+	}
+	return Where{MultiSurface: &gmlMultiSurface{SurfaceMember: polygons}}, true
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	atomfeed "github.com/pdok/atom-generator/feeds"
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatormodel "github.com/pdok/smooth-operator/model"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+)
+
+func TestGetEntryWheres(t *testing.T) {
+	baseURL, _ := url.Parse("https://test.com/path/")
+	wgs84 := getTestSRS("https://www.opengis.net/def/crs/EPSG/0/4326")
+	polygon := "POLYGON ((5 52, 6 52, 6 53, 5 52))"
+	multiPolygon := "MULTIPOLYGON (((5 52, 6 52, 6 53, 5 52)), ((7 50, 8 50, 8 51, 7 50)))"
+
+	atom := pdoknlv3.Atom{Spec: pdoknlv3.AtomSpec{Service: pdoknlv3.Service{
+		BaseURL: smoothoperatormodel.URL{URL: baseURL},
+		DatasetFeeds: []pdoknlv3.DatasetFeed{{
+			TechnicalName: "feed",
+			Archive:       &pdoknlv3.Archive{},
+			Entries: []pdoknlv3.Entry{
+				{TechnicalName: "entry-1", SRS: wgs84, Polygon: pdoknlv3.Polygon{Geometry: &multiPolygon}},
+				{TechnicalName: "entry-2", SRS: wgs84, Polygon: pdoknlv3.Polygon{Geometry: &polygon}},
+				{TechnicalName: "entry-3", SRS: wgs84, Polygon: pdoknlv3.Polygon{Geometry: &multiPolygon}, Archived: true},
+			},
+		}},
+	}}}
+
+	where := Where{MultiSurface: &gmlMultiSurface{SurfaceMember: []gmlPolygon{
+		{Exterior: gmlRing{PosList: "52 5 52 6 53 6 52 5"}},
+		{Exterior: gmlRing{PosList: "50 7 50 8 51 8 50 7"}},
+	}}}
+	want := map[string]map[string]Where{
+		"https://test.com/path/index.xml":        {"https://test.com/path/feed.xml": where},
+		"https://test.com/path/feed.xml":         {"https://test.com/path/entry-1.xml": where},
+		"https://test.com/path/feed-archive.xml": {"https://test.com/path/entry-3.xml": where},
+	}
+	if got := GetEntryWheres(atom); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetEntryWheres() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateATOM(t *testing.T) {
+	feed := atomfeed.Feed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Georss:  "http://www.georss.org/georss",
+		ID:      "https://test.com/path/feed.xml",
+		Title:   "title",
+		Rights:  "rights",
+		Updated: smoothutil.Pointer("2006-01-02T15:04:05Z"),
+		Entry: []atomfeed.Entry{{
+			ID:       "https://test.com/path/entry.xml",
+			Updated:  smoothutil.Pointer("2006-01-02T15:04:05Z"),
+			Polygon:  "0 0 0 10 10 10 0 0",
+			Category: []atomfeed.Category{{Term: "https://srs/test", Label: "srs"}},
+		}},
+	}
+
+	got, err := generateATOM(feed, nil, nil)
+	if err != nil {
+		t.Fatalf("generateATOM() error = %v", err)
+	}
+	if want := feed.GenerateATOM(); string(got) != string(want) {
+		t.Errorf("generateATOM() = %s, want %s", got, want)
+	}
+
+	holed := "POLYGON ((0 0, 10 0, 10 10, 0 0), (6 2, 8 6, 8 2, 6 2))"
+	where, ok := getWhere(pdoknlv3.Polygon{Geometry: &holed}, getTestSRS("https://www.opengis.net/def/crs/EPSG/0/4326"))
+	if !ok {
+		t.Fatal("getWhere() of a polygon with a hole should return a georss:where")
+	}
+	got, err = generateATOM(feed, nil, map[string]Where{"https://test.com/path/entry.xml": where})
+	if err != nil {
+		t.Fatalf("generateATOM() error = %v", err)
+	}
+	for _, want := range []string{
+		`xmlns:gml="http://www.opengis.net/gml"`,
+		"<georss:where>\n   <gml:Polygon>\n    <gml:exterior>\n     <gml:LinearRing>\n      <gml:posList>0 0 0 10 10 10 0 0</gml:posList>",
+		"<gml:interior>\n     <gml:LinearRing>\n      <gml:posList>2 6 6 8 2 8 2 6</gml:posList>",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("generateATOM() = %s, want it to contain %s", got, want)
+		}
+	}
+	if strings.Contains(string(got), "georss:polygon") {
+		t.Errorf("generateATOM() = %s, should not contain a georss:polygon", got)
+	}
+}
@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// rolloutPriorityLabel orders the rollout of new images, Atoms with a higher priority get the new images first
+	rolloutPriorityLabel = "pdok.nl/rollout-priority"
+
+	// maxWaitingRequeueAfter is the longest an Atom that waits for its turn goes without checking again
+	maxWaitingRequeueAfter = 5 * time.Minute
+)
+
+var (
+	imageRolloutOutdatedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "atom_image_rollout_outdated_atoms",
+		Help: "Number of Atoms that still run outdated operator images",
+	})
+	imageRolloutInProgressGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "atom_image_rollout_in_progress_atoms",
+		Help: "Number of Atoms that are rolling out new operator images",
+	})
+	imageRolloutPausedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "atom_image_rollout_paused",
+		Help: "1 when the rollout of new operator images is paused because Atoms with the new images fail",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(imageRolloutOutdatedGauge, imageRolloutInProgressGauge, imageRolloutPausedGauge)
+}
+
+// ImageRollout throttles how many Deployments roll out new operator images at the same time.
+// Until a Deployment gets its turn it keeps the images it runs. The shared server of a namespace is one Deployment,
+// which gets the highest priority of the Atoms in its namespace.
+// The progress of the rollout is derived from the Deployments in the cluster, so it survives a restart of the operator.
+type ImageRollout struct {
+	// MaxConcurrent is the maximum number of Deployments that roll out new images at the same time
+	MaxConcurrent int
+	// StateMaxAge is how long the progress of the rollout over all Deployments is reused before it is determined again
+	StateMaxAge time.Duration
+
+	// mu serializes the turns, so concurrent reconciles do not start more rollouts than allowed
+	mu sync.Mutex
+	// state is the last determined progress of the rollout, including the turns given since
+	state *fleetRolloutState
+	// determinedAt is when the state was determined
+	determinedAt time.Time
+	// started holds the Deployments that got their turn until the cache shows their new images
+	started map[types.NamespacedName]bool
+	// waiting holds the number of times the Deployments that did not get their turn yet had to wait
+	waiting map[types.NamespacedName]int
+}
+
+func NewImageRollout(maxConcurrent int) *ImageRollout {
+	return &ImageRollout{
+		MaxConcurrent: maxConcurrent,
+		StateMaxAge:   10 * time.Second,
+		started:       make(map[types.NamespacedName]bool),
+		waiting:       make(map[types.NamespacedName]int),
+	}
+}
+
+// IsWaiting returns whether the Deployment still waits for its turn to roll out the new images
+func (i *ImageRollout) IsWaiting(key types.NamespacedName) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.waiting[key] > 0
+}
+
+// getWaitingRequeueAfter returns after how long the Atom of a waiting Deployment checks again whether it is its turn.
+// Every check renders the feeds of the Atom, so the longer a Deployment waits the less often it checks.
+func (i *ImageRollout) getWaitingRequeueAfter(key types.NamespacedName) (requeueAfter time.Duration, waiting bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	checks := i.waiting[key]
+	if checks == 0 {
+		return 0, false
+	}
+	return min(rolloutRequeueAfter<<min(checks-1, 4), maxWaitingRequeueAfter), true
+}
+
+// fleetRolloutState is the progress of the rollout of new images over all Deployments
+type fleetRolloutState struct {
+	// outdated are the Deployments that wait for new images, in the order they get them
+	outdated []types.NamespacedName
+	// inProgress is the number of Deployments that are rolling out
+	inProgress int
+	// failing are the Deployments that fail to roll out
+	failing []types.NamespacedName
+}
+
+// start gives the outdated Deployment its turn
+func (s *fleetRolloutState) start(key types.NamespacedName) {
+	s.outdated = slices.DeleteFunc(s.outdated, func(outdated types.NamespacedName) bool {
+		return outdated == key
+	})
+	s.inProgress++
+}
+
+func (s *fleetRolloutState) updateMetrics() {
+	imageRolloutOutdatedGauge.Set(float64(len(s.outdated)))
+	imageRolloutInProgressGauge.Set(float64(s.inProgress))
+	if len(s.failing) > 0 {
+		imageRolloutPausedGauge.Set(1)
+	} else {
+		imageRolloutPausedGauge.Set(0)
+	}
+}
+
+// rolloutDeployment is a Deployment that serves feeds, with the Atom it serves or nil for a shared server
+type rolloutDeployment struct {
+	deployment  *appsv1.Deployment
+	replicaSets []appsv1.ReplicaSet
+	atom        *pdoknlv3.Atom
+	priority    int
+}
+
+// throttleImageRollout puts the current images back in the pod spec of the Deployment when it has to wait
+// before it may roll out new operator images. Images that are overridden by the Atom are never held back.
+// The Deployment is the current one, with the pod spec that is about to be applied. The atom is nil for a shared server.
+func (r *AtomReconciler) throttleImageRollout(ctx context.Context, atom *pdoknlv3.Atom, currentImages map[string]string, deployment *appsv1.Deployment) error {
+	rollout := r.ImageRollout
+	key := client.ObjectKeyFromObject(deployment)
+	var eventObj client.Object = deployment
+	if atom != nil {
+		eventObj = atom
+	}
+
+	rollout.mu.Lock()
+	defer rollout.mu.Unlock()
+
+	// The state is also determined for Deployments that are not outdated, so the metrics follow the end of the rollout
+	state, err := r.getFleetRolloutState(ctx)
+	if err != nil {
+		return err
+	}
+	containers := getOutdatedContainers(atom, currentImages, &deployment.Spec.Template.Spec)
+	if len(containers) == 0 {
+		delete(rollout.waiting, key)
+		return nil
+	}
+	if rollout.started[key] {
+		return nil
+	}
+
+	switch index := slices.Index(state.outdated, key); {
+	case len(state.failing) > 0:
+		r.recordEvent(eventObj, corev1.EventTypeWarning, "ImageRolloutPaused",
+			fmt.Sprintf("The rollout of new images is paused, because %s fail to roll out", joinKeys(state.failing)))
+	case index >= 0 && index < rollout.MaxConcurrent-state.inProgress:
+		rollout.started[key] = true
+		delete(rollout.waiting, key)
+		state.start(key)
+		state.updateMetrics()
+		r.recordEvent(eventObj, corev1.EventTypeNormal, "ImageRolloutStarted",
+			fmt.Sprintf("Rolling out new images, %d Deployments are waiting", len(state.outdated)))
+		return nil
+	}
+
+	rollout.waiting[key]++
+	for _, container := range containers {
+		container.Image = currentImages[container.Name]
+	}
+	return nil
+}
+
+// getFleetRolloutState returns the progress of the rollout of new images over the Deployments of all namespaces.
+// It is determined again when it is older than the StateMaxAge, the caller holds the lock of the image rollout.
+func (r *AtomReconciler) getFleetRolloutState(ctx context.Context) (*fleetRolloutState, error) {
+	rollout := r.ImageRollout
+	if rollout.state != nil && time.Since(rollout.determinedAt) < rollout.StateMaxAge {
+		return rollout.state, nil
+	}
+
+	deployments, err := r.getRolloutDeployments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &fleetRolloutState{}
+	started := make(map[types.NamespacedName]bool)
+	for _, rolloutDeployment := range deployments {
+		deployment := rolloutDeployment.deployment
+		key := client.ObjectKeyFromObject(deployment)
+		settings, err := r.getSettings(ctx, deployment.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		podSpec := deployment.Spec.Template.Spec.DeepCopy()
+		setTargetImages(rolloutDeployment.atom, settings, podSpec)
+		outdated := len(getOutdatedContainers(rolloutDeployment.atom, getContainerImages(&deployment.Spec.Template.Spec), podSpec)) > 0
+		rollingOut := !isRolledOut(deployment) && runsOtherImages(rolloutDeployment.replicaSets, getContainerImages(podSpec))
+		switch {
+		case outdated && rollout.started[key]:
+			// The cache does not show the new images of this Deployment yet
+			started[key] = true
+			state.inProgress++
+		case outdated:
+			state.outdated = append(state.outdated, key)
+		case rollingOut:
+			state.inProgress++
+			atomDegraded := rolloutDeployment.atom != nil && meta.IsStatusConditionTrue(rolloutDeployment.atom.Status.Conditions, degradedConditionType)
+			if getDeadlineExceeded(deployment) != nil || atomDegraded {
+				state.failing = append(state.failing, key)
+			}
+		}
+	}
+	rollout.started = started
+
+	state.updateMetrics()
+	rollout.state, rollout.determinedAt = state, time.Now()
+	return state, nil
+}
+
+// getRolloutDeployments returns the Deployments that serve the feeds of the Atoms, in the order they get new images
+func (r *AtomReconciler) getRolloutDeployments(ctx context.Context) ([]rolloutDeployment, error) {
+	atomList := &pdoknlv3.AtomList{}
+	if err := r.List(ctx, atomList); err != nil {
+		return nil, fmt.Errorf("unable to list the atoms: %w", err)
+	}
+	selector, err := getServerSelector()
+	if err != nil {
+		return nil, err
+	}
+	deploymentList := &appsv1.DeploymentList{}
+	if err = r.List(ctx, deploymentList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list the deployments: %w", err)
+	}
+	deployments := make(map[types.NamespacedName]*appsv1.Deployment)
+	for i := range deploymentList.Items {
+		deployments[client.ObjectKeyFromObject(&deploymentList.Items[i])] = &deploymentList.Items[i]
+	}
+	replicaSetList := &appsv1.ReplicaSetList{}
+	if err = r.List(ctx, replicaSetList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list the replicasets: %w", err)
+	}
+	replicaSets := make(map[types.NamespacedName][]appsv1.ReplicaSet)
+	for _, replicaSet := range replicaSetList.Items {
+		if owner := metav1.GetControllerOf(&replicaSet); owner != nil && owner.Kind == "Deployment" {
+			key := types.NamespacedName{Namespace: replicaSet.Namespace, Name: owner.Name}
+			replicaSets[key] = append(replicaSets[key], replicaSet)
+		}
+	}
+
+	var rolloutDeployments []rolloutDeployment
+	namespacePriorities := make(map[string]int)
+	for i := range atomList.Items {
+		atom := &atomList.Items[i]
+		if !atom.DeletionTimestamp.IsZero() {
+			continue
+		}
+		priority := getRolloutPriority(atom)
+		if namespacePriority, ok := namespacePriorities[atom.Namespace]; !ok || priority > namespacePriority {
+			namespacePriorities[atom.Namespace] = priority
+		}
+		if key := client.ObjectKeyFromObject(getBareDeployment(atom)); deployments[key] != nil {
+			rolloutDeployments = append(rolloutDeployments, rolloutDeployment{
+				deployment: deployments[key], replicaSets: replicaSets[key], atom: atom, priority: priority,
+			})
+		}
+	}
+	for namespace, priority := range namespacePriorities {
+		if key := client.ObjectKeyFromObject(getBareSharedServerDeployment(namespace)); deployments[key] != nil {
+			rolloutDeployments = append(rolloutDeployments, rolloutDeployment{deployment: deployments[key], replicaSets: replicaSets[key], priority: priority})
+		}
+	}
+
+	slices.SortFunc(rolloutDeployments, compareRolloutPriority)
+	return rolloutDeployments, nil
+}
+
+// runsOtherImages returns whether pods of the ReplicaSets of a Deployment still run other images than the target images,
+// which means the Deployment is rolling out new images rather than for example new feeds
+func runsOtherImages(replicaSets []appsv1.ReplicaSet, targetImages map[string]string) bool {
+	for _, replicaSet := range replicaSets {
+		if replicaSet.Status.Replicas == 0 {
+			continue
+		}
+		for name, image := range getContainerImages(&replicaSet.Spec.Template.Spec) {
+			if targetImage, ok := targetImages[name]; ok && targetImage != image {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setTargetImages sets the images the Deployment that serves the feeds should run in its pod spec, the atom is nil for a shared server
+func setTargetImages(atom *pdoknlv3.Atom, settings pdoknlv3.OperatorSettings, podSpec *corev1.PodSpec) {
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == "atom-generator" && atom != nil {
+			podSpec.InitContainers[i].Image = getAtomGeneratorImage(atom, settings)
+		}
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "atom-service" {
+			continue
+		}
+		if atom != nil {
+			podSpec.Containers[i].Image = getLighttpdImage(atom, settings)
+		} else {
+			podSpec.Containers[i].Image = settings.LighttpdImage
+		}
+	}
+}
+
+// getOutdatedContainers returns the containers in the pod spec that get another image than they currently run,
+// because the image of the operator changed. The atom is nil for a shared server, which has no image overrides.
+func getOutdatedContainers(atom *pdoknlv3.Atom, currentImages map[string]string, podSpec *corev1.PodSpec) []*corev1.Container {
+	var images *pdoknlv3.Images
+	if atom != nil {
+		images = atom.Spec.Images
+	}
+	var containers []*corev1.Container
+	for _, list := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range list {
+			container := &list[i]
+			currentImage, ok := currentImages[container.Name]
+			if !ok || currentImage == container.Image {
+				continue
+			}
+			if images != nil && ((container.Name == "atom-generator" && images.AtomGenerator != nil) ||
+				(container.Name == "atom-service" && images.Lighttpd != nil)) {
+				continue
+			}
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// getContainerImages returns the images of the (init) containers in the pod spec by container name
+func getContainerImages(podSpec *corev1.PodSpec) map[string]string {
+	images := make(map[string]string)
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+		images[container.Name] = container.Image
+	}
+	return images
+}
+
+// compareRolloutPriority orders Deployments by descending rollout priority and then by namespace and name
+func compareRolloutPriority(a, b rolloutDeployment) int {
+	return cmp.Or(
+		cmp.Compare(b.priority, a.priority),
+		strings.Compare(a.deployment.Namespace, b.deployment.Namespace),
+		strings.Compare(a.deployment.Name, b.deployment.Name),
+	)
+}
+
+func getRolloutPriority(atom *pdoknlv3.Atom) int {
+	priority, err := strconv.Atoi(atom.Labels[rolloutPriorityLabel])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+func (r *AtomReconciler) recordEvent(obj client.Object, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventType, reason, message)
+	}
+}
+
+func joinKeys(keys []types.NamespacedName) string {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, key.String())
+	}
+	return strings.Join(names, ", ")
+}
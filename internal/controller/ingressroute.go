@@ -58,13 +58,10 @@ func (r *AtomReconciler) mutateIngressRoute(atom *pdoknlv3.Atom, ingressRoute *t
 		return downloadMiddlewares[i].Name < downloadMiddlewares[j].Name
 	})
 
+	backend := r.getFeedBackend(atom)
 	ingressRoute.Spec.Routes = []traefikiov1alpha1.Route{}
-	if len(atom.Spec.IngressRouteURLs) > 0 {
-		for _, ingressRouteURL := range atom.Spec.IngressRouteURLs {
-			ingressRoute.Spec.Routes = append(ingressRoute.Spec.Routes, getRoutesForURL(atom, ingressRouteURL.URL, downloadMiddlewares)...)
-		}
-	} else {
-		ingressRoute.Spec.Routes = getRoutesForURL(atom, atom.Spec.Service.BaseURL, downloadMiddlewares)
+	for _, ingressRouteURL := range atom.GetIngressRouteURLs() {
+		ingressRoute.Spec.Routes = append(ingressRoute.Spec.Routes, getRoutesForURL(atom, ingressRouteURL.URL, downloadMiddlewares, backend, !r.LegacyAtomGenerator)...)
 	}
 
 	if err := smoothutil.EnsureSetGVK(r.Client, ingressRoute, ingressRoute); err != nil {
@@ -83,56 +80,118 @@ func getMatchRule(url *url.URL, pathPrefix bool) string {
 	return fmt.Sprintf("%s && %s", host, path)
 }
 
-func getDefaultRule(atom *pdoknlv3.Atom, matchRule string) traefikiov1alpha1.Route {
-	return traefikiov1alpha1.Route{
-		Kind:  "Rule",
-		Match: matchRule,
-		Services: []traefikiov1alpha1.Service{
-			{
-				LoadBalancerSpec: traefikiov1alpha1.LoadBalancerSpec{
-					Name: getBareService(atom).GetName(),
-					Kind: "Service",
-					Port: intstr.FromInt32(atomPortNr),
-				},
-			},
+// feedBackend is the service that serves the feeds of an Atom, with the middlewares that map the feed paths onto it
+type feedBackend struct {
+	service     traefikiov1alpha1.Service
+	middlewares []traefikiov1alpha1.MiddlewareRef
+}
+
+func (r *AtomReconciler) getFeedBackend(atom *pdoknlv3.Atom) feedBackend {
+	headers := traefikiov1alpha1.MiddlewareRef{Name: atom.Name + headersSuffix}
+	stripPrefix := traefikiov1alpha1.MiddlewareRef{Name: atom.Name + stripPrefixSuffix}
+
+	switch {
+	case r.FeedContainer != nil:
+		return feedBackend{
+			service:     getAzureStorageService(),
+			middlewares: []traefikiov1alpha1.MiddlewareRef{headers, {Name: atom.Name + feedsSuffix}},
+		}
+	case r.SharedServer:
+		// The shared server serves the feeds of the Atom from a directory named after the Atom
+		return feedBackend{
+			service:     getAtomService(sharedServerName),
+			middlewares: []traefikiov1alpha1.MiddlewareRef{headers, stripPrefix, {Name: atom.Name + addPrefixSuffix}},
+		}
+	default:
+		return feedBackend{
+			service:     getAtomService(getBareService(atom).GetName()),
+			middlewares: []traefikiov1alpha1.MiddlewareRef{headers, stripPrefix},
+		}
+	}
+}
+
+func getAtomService(name string) traefikiov1alpha1.Service {
+	return traefikiov1alpha1.Service{
+		LoadBalancerSpec: traefikiov1alpha1.LoadBalancerSpec{
+			Name: name,
+			Kind: "Service",
+			Port: intstr.FromInt32(atomPortNr),
 		},
-		Middlewares: []traefikiov1alpha1.MiddlewareRef{
-			{
-				Name: atom.Name + headersSuffix,
-			},
-			{
-				Name: atom.Name + stripPrefixSuffix,
-			},
+	}
+}
+
+func getAzureStorageService() traefikiov1alpha1.Service {
+	return traefikiov1alpha1.Service{
+		LoadBalancerSpec: traefikiov1alpha1.LoadBalancerSpec{
+			Name:           "azure-storage",
+			Port:           intstr.IntOrString{Type: intstr.String, StrVal: "azure-storage"},
+			PassHostHeader: smoothutil.Pointer(false),
+			Kind:           "Service",
 		},
 	}
 }
 
-func getRoutesForURL(atom *pdoknlv3.Atom, url smoothoperatormodel.URL, downloadMiddlewares []traefikiov1alpha1.MiddlewareRef) []traefikiov1alpha1.Route {
-	routes := []traefikiov1alpha1.Route{
-		getDefaultRule(atom, getMatchRule(url.JoinPath("index.xml"), false)),
+func getDefaultRule(matchRule string, backend feedBackend) traefikiov1alpha1.Route {
+	return traefikiov1alpha1.Route{
+		Kind:        "Rule",
+		Match:       matchRule,
+		Services:    []traefikiov1alpha1.Service{backend.service},
+		Middlewares: backend.middlewares,
 	}
+}
 
-	// Set additional routes per datasetFeed
+// htmlAcceptRule matches the requests for the HTML page of the service feed. The q-values of the Accept header are not
+// weighed, so a client that accepts HTML gets the HTML page, unless it accepts the Atom feed itself as well.
+// Browsers never ask for application/atom+xml, feed readers do, even when they also accept HTML with a lower q-value.
+const htmlAcceptRule = "HeaderRegexp(`Accept`, `text/html`) && !HeaderRegexp(`Accept`, `application/atom\\+xml`)"
+
+// getIndexRule returns the route from the base URL to the index file of the service feed, for the requests that match the extra rule
+func getIndexRule(atom *pdoknlv3.Atom, url smoothoperatormodel.URL, format string, extraRule string, backend feedBackend) traefikiov1alpha1.Route {
+	host := fmt.Sprintf("(Host(`localhost`) || Host(`%s`))", url.Hostname())
+	matchRule := fmt.Sprintf("%s && (Path(`%s`) || Path(`%s/`))", host, url.Path, url.Path)
+	if extraRule != "" {
+		matchRule += " && " + extraRule
+	}
+	backend.middlewares = append([]traefikiov1alpha1.MiddlewareRef{{Name: atom.Name + indexSuffix + format}}, backend.middlewares...)
+	return getDefaultRule(matchRule, backend)
+}
+
+// getRoutesForURL returns the routes to the feeds and downloads of the Atom on the URL.
+// When the operator renders the feeds, the HTML page of every feed and the DCAT documents are routed as well, just like
+// the JSON of every feed when the Atom has records. The base URL then serves the HTML page of the service feed to
+// clients that accept HTML but not the Atom feed, see htmlAcceptRule, and the service feed itself to others.
+func getRoutesForURL(atom *pdoknlv3.Atom, url smoothoperatormodel.URL, downloadMiddlewares []traefikiov1alpha1.MiddlewareRef, backend feedBackend, rendered bool) []traefikiov1alpha1.Route {
+	fileNames := []string{"index.xml"}
 	for _, datasetFeed := range atom.Spec.Service.DatasetFeeds {
-		matchRule := getMatchRule(url.JoinPath(datasetFeed.TechnicalName+".xml"), false)
-		rule := getDefaultRule(atom, matchRule)
-		routes = append(routes, rule)
+		fileNames = append(fileNames, datasetFeed.GetFeedFileNames()...)
+	}
+
+	var routes []traefikiov1alpha1.Route
+	for _, feedFileName := range fileNames {
+		routedFileNames := []string{feedFileName}
+		if rendered {
+			routedFileNames = atom.Spec.Service.GetRenderedFileNames(feedFileName)
+		}
+		for _, fileName := range routedFileNames {
+			routes = append(routes, getDefaultRule(getMatchRule(url.JoinPath(fileName), false), backend))
+		}
+	}
+
+	if rendered {
+		for _, fileName := range []string{pdoknlv3.DCATJSONLDFileName, pdoknlv3.DCATRDFFileName} {
+			routes = append(routes, getDefaultRule(getMatchRule(url.JoinPath(fileName), false), backend))
+		}
+		routes = append(routes,
+			getIndexRule(atom, url, "html", htmlAcceptRule, backend),
+			getIndexRule(atom, url, "xml", "", backend),
+		)
 	}
 
 	// Add Azure storage rule
 	azureStorageRule := traefikiov1alpha1.Route{
-		Kind:  "Rule",
-		Match: getMatchRule(url.JoinPath("downloads/"), true),
-		Services: []traefikiov1alpha1.Service{
-			{
-				LoadBalancerSpec: traefikiov1alpha1.LoadBalancerSpec{
-					Name:           "azure-storage",
-					Port:           intstr.IntOrString{Type: intstr.String, StrVal: "azure-storage"},
-					PassHostHeader: smoothutil.Pointer(false),
-					Kind:           "Service",
-				},
-			},
-		},
+		Kind:     "Rule",
+		Match:    getMatchRule(url.JoinPath("downloads/"), true),
+		Services: []traefikiov1alpha1.Service{getAzureStorageService()},
 		Middlewares: append([]traefikiov1alpha1.MiddlewareRef{
 			{
 				Name: atom.Name + headersSuffix,
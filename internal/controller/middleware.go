@@ -42,6 +42,96 @@ func (r *AtomReconciler) mutateStripPrefixMiddleware(atom *pdoknlv3.Atom, middle
 	return ctrl.SetControllerReference(atom, middleware, r.Scheme)
 }
 
+func getBareAddPrefixMiddleware(obj metav1.Object) *traefikiov1alpha1.Middleware {
+	return &traefikiov1alpha1.Middleware{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: obj.GetName() + addPrefixSuffix,
+			// name might become too long. not handling here. will just fail on apply.
+			Namespace: obj.GetNamespace(),
+		},
+	}
+}
+
+// mutateAddPrefixMiddleware points the stripped path to the directory of the Atom on the shared server
+func (r *AtomReconciler) mutateAddPrefixMiddleware(atom *pdoknlv3.Atom, middleware *traefikiov1alpha1.Middleware) error {
+	middleware.Labels = getObjectLabels(atom, middleware.Labels)
+
+	middleware.Spec = traefikiov1alpha1.MiddlewareSpec{
+		AddPrefix: &dynamic.AddPrefix{
+			Prefix: "/" + atom.Name,
+		},
+	}
+
+	if err := smoothutil.EnsureSetGVK(r.Client, middleware, middleware); err != nil {
+		return err
+	}
+	return ctrl.SetControllerReference(atom, middleware, r.Scheme)
+}
+
+func getBareFeedsMiddleware(obj metav1.Object) *traefikiov1alpha1.Middleware {
+	return &traefikiov1alpha1.Middleware{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: obj.GetName() + feedsSuffix,
+			// name might become too long. not handling here. will just fail on apply.
+			Namespace: obj.GetNamespace(),
+		},
+	}
+}
+
+// mutateFeedsMiddleware points the feed paths to the published feeds of the Atom in the blob storage
+func (r *AtomReconciler) mutateFeedsMiddleware(atom *pdoknlv3.Atom, middleware *traefikiov1alpha1.Middleware) error {
+	middleware.Labels = getObjectLabels(atom, middleware.Labels)
+
+	paths := []string{}
+	for _, ingressRouteURL := range atom.GetIngressRouteURLs() {
+		paths = append(paths, ingressRouteURL.URL.Path)
+	}
+
+	middleware.Spec = traefikiov1alpha1.MiddlewareSpec{
+		ReplacePathRegex: &dynamic.ReplacePathRegex{
+			Regex:       "^(" + strings.Join(paths, "|") + ")/(.*)",
+			Replacement: r.FeedContainer.GetURLPath(getFeedsDir(atom)) + "/$2",
+		},
+	}
+
+	if err := smoothutil.EnsureSetGVK(r.Client, middleware, middleware); err != nil {
+		return err
+	}
+	return ctrl.SetControllerReference(atom, middleware, r.Scheme)
+}
+
+func getBareIndexMiddleware(obj metav1.Object, format string) *traefikiov1alpha1.Middleware {
+	return &traefikiov1alpha1.Middleware{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: obj.GetName() + indexSuffix + format,
+			// name might become too long. not handling here. will just fail on apply.
+			Namespace: obj.GetNamespace(),
+		},
+	}
+}
+
+// mutateIndexMiddleware points requests to the base URL of the Atom to the index file of the service feed
+func (r *AtomReconciler) mutateIndexMiddleware(atom *pdoknlv3.Atom, fileName string, middleware *traefikiov1alpha1.Middleware) error {
+	middleware.Labels = getObjectLabels(atom, middleware.Labels)
+
+	paths := []string{}
+	for _, ingressRouteURL := range atom.GetIngressRouteURLs() {
+		paths = append(paths, ingressRouteURL.URL.Path)
+	}
+
+	middleware.Spec = traefikiov1alpha1.MiddlewareSpec{
+		ReplacePathRegex: &dynamic.ReplacePathRegex{
+			Regex:       "^(" + strings.Join(paths, "|") + ")/?$",
+			Replacement: "$1/" + fileName,
+		},
+	}
+
+	if err := smoothutil.EnsureSetGVK(r.Client, middleware, middleware); err != nil {
+		return err
+	}
+	return ctrl.SetControllerReference(atom, middleware, r.Scheme)
+}
+
 func getBareHeadersMiddleware(obj metav1.Object) *traefikiov1alpha1.Middleware {
 	return &traefikiov1alpha1.Middleware{
 		ObjectMeta: metav1.ObjectMeta{
@@ -90,14 +180,9 @@ func getBareDownloadLinkMiddleware(obj metav1.Object, index int) *traefikiov1alp
 func (r *AtomReconciler) mutateDownloadLinkMiddleware(atom *pdoknlv3.Atom, prefix string, files []string, middleware *traefikiov1alpha1.Middleware) error {
 	middleware.Labels = getObjectLabels(atom, middleware.Labels)
 
-	ingressRouteURLs := atom.Spec.IngressRouteURLs
-	if len(ingressRouteURLs) == 0 {
-		ingressRouteURLs = smoothoperatormodel.IngressRouteURLs{{URL: atom.Spec.Service.BaseURL}}
-	}
-
 	middleware.Spec = traefikiov1alpha1.MiddlewareSpec{
 		ReplacePathRegex: &dynamic.ReplacePathRegex{
-			Regex:       getDownloadLinkRegex(ingressRouteURLs, files),
+			Regex:       getDownloadLinkRegex(atom.GetIngressRouteURLs(), files),
 			Replacement: "/" + prefix + "/$2",
 		},
 	}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	feedContentType = "application/atom+xml"
+	htmlContentType = "text/html; charset=utf-8"
+
+	// publishedFeedsFinalizer keeps a deleted Atom until its feeds are deleted from the blob storage
+	publishedFeedsFinalizer = "pdok.nl/published-feeds"
+)
+
+var publishedContentTypes = map[string]string{
+	".html":   htmlContentType,
+	".json":   "application/json",
+	".jsonld": "application/ld+json",
+	".rdf":    "application/rdf+xml",
+}
+
+// publishFeeds renders the feeds, their HTML pages and the DCAT documents and writes them to the blob storage, instead of a ConfigMap.
+// It returns whether any of the published feeds changed.
+func (r *AtomReconciler) publishFeeds(ctx context.Context, atom *pdoknlv3.Atom, ownerInfo *smoothoperatorv1.OwnerInfo, settings pdoknlv3.OperatorSettings) (changed bool, err error) {
+	renderedFeeds, err := r.getRenderedFeeds(atom, ownerInfo, settings.BlobEndpoint)
+	if err != nil {
+		return false, err
+	}
+
+	if changed, err = r.FeedContainer.Publish(ctx, getFeedsDir(atom), renderedFeeds, getPublishedContentType); err != nil {
+		return changed, fmt.Errorf("failed to publish the feeds: %w", err)
+	}
+	return changed, nil
+}
+
+// finalizePublishedFeeds adds the finalizer that deletes the published feeds to the Atom, and deletes them when the Atom is deleted.
+// It returns whether the Atom is being deleted, in which case there is nothing left to reconcile.
+func (r *AtomReconciler) finalizePublishedFeeds(ctx context.Context, atom *pdoknlv3.Atom) (deleting bool, err error) {
+	if atom.DeletionTimestamp.IsZero() {
+		if r.FeedContainer == nil || !controllerutil.AddFinalizer(atom, publishedFeedsFinalizer) {
+			return false, nil
+		}
+		return false, r.Update(ctx, atom)
+	}
+	if !controllerutil.ContainsFinalizer(atom, publishedFeedsFinalizer) {
+		return true, nil
+	}
+
+	// Without a container the operator no longer publishes, so the feeds can only be left behind
+	if r.FeedContainer != nil {
+		if _, err = r.FeedContainer.Publish(ctx, getFeedsDir(atom), nil, getPublishedContentType); err != nil {
+			return true, fmt.Errorf("failed to delete the published feeds: %w", err)
+		}
+	}
+	controllerutil.RemoveFinalizer(atom, publishedFeedsFinalizer)
+	return true, r.Update(ctx, atom)
+}
+
+// getPublishedContentType returns the content type of a published feed, its HTML page or JSON, or a DCAT document
+func getPublishedContentType(fileName string) string {
+	if contentType, ok := publishedContentTypes[path.Ext(fileName)]; ok {
+		return contentType
+	}
+	return feedContentType
+}
+
+// getFeedsDir returns the directory in the blob storage that contains the feeds of the Atom
+func getFeedsDir(atom *pdoknlv3.Atom) string {
+	return atom.Namespace + "/" + atom.Name
+}
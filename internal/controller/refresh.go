@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// getRefreshInterval returns the interval between refreshes of the feeds of the Atom, zero when refreshing is disabled.
+// The atom-generator init container only requests the downloads when a pod starts, so legacy mode is never refreshed.
+func (r *AtomReconciler) getRefreshInterval(atom *pdoknlv3.Atom) time.Duration {
+	if r.LegacyAtomGenerator {
+		return 0
+	}
+	if atom.Spec.Refresh != nil {
+		return atom.Spec.Refresh.Interval.Duration
+	}
+	return r.RefreshInterval
+}
+
+// getNextRefresh returns whether the feeds are due for a refresh at now, and after how long the Atom should be reconciled again
+func getNextRefresh(atom *pdoknlv3.Atom, interval time.Duration, now time.Time) (due bool, requeueAfter time.Duration) {
+	refreshStatus := atom.Status.Refresh
+	if refreshStatus == nil {
+		return true, interval
+	}
+	nextRefresh := refreshStatus.LastRefreshTime.Add(interval)
+	if !nextRefresh.After(now) {
+		return true, interval
+	}
+	return false, nextRefresh.Sub(now)
+}
+
+// updateRefreshStatus records the refresh in the status of the Atom.
+// Only a due refresh is recorded, recording every reconcile would trigger a new reconcile through the status update.
+func (r *AtomReconciler) updateRefreshStatus(ctx context.Context, atom *pdoknlv3.Atom, feedsChanged bool, refreshErr error) {
+	lgr := logf.FromContext(ctx)
+
+	refreshStatus := &pdoknlv3.RefreshStatus{
+		LastRefreshTime: metav1.NewTime(time.Now()),
+		Result:          pdoknlv3.RefreshResultUnchanged,
+	}
+	switch {
+	case refreshErr != nil:
+		refreshStatus.Result = pdoknlv3.RefreshResultFailed
+		refreshStatus.Message = refreshErr.Error()
+	case feedsChanged:
+		refreshStatus.Result = pdoknlv3.RefreshResultChanged
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(atom), atom); err != nil {
+		lgr.Error(err, "unable to update refresh status")
+		return
+	}
+	atom.Status.Refresh = refreshStatus
+	if err := r.Status().Update(ctx, atom); err != nil {
+		lgr.Error(err, "unable to update refresh status")
+	}
+}
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	progressingConditionType = "Progressing"
+	availableConditionType   = "Available"
+	degradedConditionType    = "Degraded"
+
+	// rolloutRequeueAfter is how often a rollout is checked, pods are not watched
+	rolloutRequeueAfter = 30 * time.Second
+
+	// deploymentRevisionAnnotation is the revision the Deployment controller gives a Deployment and its newest ReplicaSet
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// failingWaitingReasons are the reasons of waiting containers that will not start without intervention
+var failingWaitingReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError"}
+
+// GetCacheByObject restricts the cache of the manager to the ReplicaSets and Pods of the Deployments that serve feeds,
+// so the rollout status does not cache every ReplicaSet and Pod in the cluster
+func GetCacheByObject() (map[client.Object]cache.ByObject, error) {
+	selector, err := getServerSelector()
+	if err != nil {
+		return nil, err
+	}
+	return map[client.Object]cache.ByObject{
+		&appsv1.ReplicaSet{}: {Label: selector},
+		&corev1.Pod{}:        {Label: selector},
+	}, nil
+}
+
+// getServerSelector selects the Deployments that serve feeds, of a single Atom or shared, and their ReplicaSets and Pods
+func getServerSelector() (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(appLabelKey, selection.In, []string{appName, sharedServerName})
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
+
+// getBareServerDeployment returns the Deployment that serves the feeds of the Atom
+func (r *AtomReconciler) getBareServerDeployment(atom *pdoknlv3.Atom) *appsv1.Deployment {
+	if r.SharedServer {
+		return getBareSharedServerDeployment(atom.Namespace)
+	}
+	return getBareDeployment(atom)
+}
+
+// updateRolloutStatus sets the Progressing, Available and Degraded conditions and the running images of the Atom
+// from the rollout of the Deployment that serves its feeds. It returns whether the rollout is still progressing.
+func (r *AtomReconciler) updateRolloutStatus(ctx context.Context, atom *pdoknlv3.Atom) (progressing bool) {
+	lgr := logf.FromContext(ctx)
+	if r.FeedContainer != nil {
+		// Published feeds are not served by a Deployment
+		return false
+	}
+
+	deployment := r.getBareServerDeployment(atom)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
+		lgr.Error(err, "unable to get deployment for rollout status")
+		return false
+	}
+	replicaSetList := &appsv1.ReplicaSetList{}
+	if err := r.List(ctx, replicaSetList, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		lgr.Error(err, "unable to list replicasets for rollout status")
+		return false
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		lgr.Error(err, "unable to list pods for rollout status")
+		return false
+	}
+
+	templateHash := getPodTemplateHash(deployment, replicaSetList.Items)
+	conditions := getRolloutConditions(deployment, templateHash, podList.Items, atom.Generation, time.Now())
+	images := getRunningImages(podList.Items)
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(atom), atom); err != nil {
+		lgr.Error(err, "unable to update rollout status")
+		return false
+	}
+	changed := !equality.Semantic.DeepEqual(atom.Status.Images, images)
+	atom.Status.Images = images
+	for _, condition := range conditions {
+		if meta.SetStatusCondition(&atom.Status.Conditions, condition) {
+			changed = true
+		}
+	}
+	if changed {
+		if err := r.Status().Update(ctx, atom); err != nil {
+			lgr.Error(err, "unable to update rollout status")
+		}
+	}
+	return meta.IsStatusConditionTrue(conditions, progressingConditionType)
+}
+
+// getRolloutConditions follows the rollout of the pods with the pod-template-hash of the current pod template of the Deployment
+func getRolloutConditions(deployment *appsv1.Deployment, templateHash string, pods []corev1.Pod, generation int64, now time.Time) []metav1.Condition {
+	var currentPods []corev1.Pod
+	for _, pod := range pods {
+		if templateHash != "" && pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey] == templateHash {
+			currentPods = append(currentPods, pod)
+		}
+	}
+	podFailure := getPodFailure(currentPods)
+
+	replicas := getReplicas(deployment)
+	status := deployment.Status
+	rolledOut := isRolledOut(deployment)
+	deadlineExceeded := getDeadlineExceeded(deployment)
+
+	var available *appsv1.DeploymentCondition
+	for _, condition := range status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			available = &condition
+		}
+	}
+
+	newCondition := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: generation,
+			LastTransitionTime: metav1.NewTime(now),
+		}
+	}
+
+	var progressing, degraded metav1.Condition
+	switch {
+	case rolledOut:
+		progressing = newCondition(progressingConditionType, metav1.ConditionFalse, "RolloutComplete",
+			fmt.Sprintf("all %d pods of deployment %s are updated and available", replicas, deployment.Name))
+	case podFailure != "" || deadlineExceeded != nil:
+		progressing = newCondition(progressingConditionType, metav1.ConditionFalse, "RolloutFailed",
+			fmt.Sprintf("the rollout of deployment %s does not progress", deployment.Name))
+	default:
+		progressing = newCondition(progressingConditionType, metav1.ConditionTrue, "RollingOut",
+			fmt.Sprintf("%d of %d pods of deployment %s are updated", status.UpdatedReplicas, replicas, deployment.Name))
+	}
+	switch {
+	case podFailure != "":
+		degraded = newCondition(degradedConditionType, metav1.ConditionTrue, "PodFailing", podFailure)
+	case deadlineExceeded != nil:
+		degraded = newCondition(degradedConditionType, metav1.ConditionTrue, "ProgressDeadlineExceeded", deadlineExceeded.Message)
+	default:
+		degraded = newCondition(degradedConditionType, metav1.ConditionFalse, "NoFailures", "")
+	}
+
+	availableCondition := newCondition(availableConditionType, metav1.ConditionFalse, "MinimumReplicasUnavailable",
+		fmt.Sprintf("deployment %s does not have minimum availability", deployment.Name))
+	if available != nil && available.Status == corev1.ConditionTrue {
+		availableCondition = newCondition(availableConditionType, metav1.ConditionTrue, "MinimumReplicasAvailable",
+			fmt.Sprintf("deployment %s has minimum availability", deployment.Name))
+	}
+
+	return []metav1.Condition{progressing, availableCondition, degraded}
+}
+
+// getPodTemplateHash returns the pod-template-hash of the newest ReplicaSet of the Deployment, which runs its current pod template.
+// It is empty when the Deployment controller did not create that ReplicaSet yet.
+func getPodTemplateHash(deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet) string {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		// The revision of the Deployment still belongs to the previous pod template
+		return ""
+	}
+	revision := deployment.Annotations[deploymentRevisionAnnotation]
+	for _, replicaSet := range replicaSets {
+		if metav1.IsControlledBy(&replicaSet, deployment) && revision != "" && replicaSet.Annotations[deploymentRevisionAnnotation] == revision {
+			return replicaSet.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		}
+	}
+	return ""
+}
+
+func getReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas != nil {
+		return *deployment.Spec.Replicas
+	}
+	return 1
+}
+
+// isRolledOut returns whether all pods of the Deployment run its current pod template and are available
+func isRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := getReplicas(deployment)
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == replicas && status.Replicas == replicas && status.AvailableReplicas == replicas
+}
+
+// getDeadlineExceeded returns the Progressing condition of the Deployment when its rollout exceeded the progress deadline
+func getDeadlineExceeded(deployment *appsv1.Deployment) *appsv1.DeploymentCondition {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return &condition
+		}
+	}
+	return nil
+}
+
+// getPodFailure returns why a container of the pods fails, or an empty string.
+// The atom-generator falls back to its last log lines as termination message, so these end up in the failure.
+func getPodFailure(pods []corev1.Pod) string {
+	pods = slices.SortedFunc(slices.Values(pods), func(a, b corev1.Pod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, pod := range pods {
+		for _, containerStatus := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+			terminated := containerStatus.State.Terminated
+			if terminated == nil && containerStatus.State.Waiting != nil {
+				// A container that is restarted after a failure waits in CrashLoopBackOff
+				terminated = containerStatus.LastTerminationState.Terminated
+			}
+			if terminated != nil && terminated.ExitCode != 0 {
+				message := strings.TrimSpace(terminated.Message)
+				if message == "" {
+					message = terminated.Reason
+				}
+				return fmt.Sprintf("container %s of pod %s failed with exit code %d: %s", containerStatus.Name, pod.Name, terminated.ExitCode, message)
+			}
+			if waiting := containerStatus.State.Waiting; waiting != nil && slices.Contains(failingWaitingReasons, waiting.Reason) {
+				return fmt.Sprintf("container %s of pod %s cannot start: %s: %s", containerStatus.Name, pod.Name, waiting.Reason, waiting.Message)
+			}
+		}
+	}
+	return ""
+}
+
+// getRunningImages returns the distinct images of the containers of the pods that are not terminated, sorted by container
+func getRunningImages(pods []corev1.Pod) []pdoknlv3.ContainerImage {
+	var images []pdoknlv3.ContainerImage
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			image := pdoknlv3.ContainerImage{Container: container.Name, Image: container.Image}
+			if !slices.Contains(images, image) {
+				images = append(images, image)
+			}
+		}
+	}
+	slices.SortFunc(images, func(a, b pdoknlv3.ContainerImage) int {
+		return cmp.Or(strings.Compare(a.Container, b.Container), strings.Compare(a.Image, b.Image))
+	})
+	return images
+}
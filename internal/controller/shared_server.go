@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var sharedServerLabels = map[string]string{appLabelKey: sharedServerName}
+
+func getBareSharedServerDeployment(namespace string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sharedServerName,
+			Namespace: namespace,
+		},
+	}
+}
+
+func getBareSharedServerService(namespace string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sharedServerName,
+			Namespace: namespace,
+		},
+	}
+}
+
+func getBareSharedServerPodDisruptionBudget(namespace string) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sharedServerName,
+			Namespace: namespace,
+		},
+	}
+}
+
+// createOrUpdateSharedServer makes the shared server of the namespace serve the feeds of all Atoms in that namespace.
+// The shared server is removed when there are no Atoms left.
+func (r *AtomReconciler) createOrUpdateSharedServer(ctx context.Context, namespace string) (operationResults map[string]controllerutil.OperationResult, err error) {
+	operationResults = make(map[string]controllerutil.OperationResult)
+	c := r.Client
+
+	settings, err := r.getSettings(ctx, namespace)
+	if err != nil {
+		return operationResults, err
+	}
+
+	atomList := &pdoknlv3.AtomList{}
+	if err = c.List(ctx, atomList, client.InNamespace(namespace)); err != nil {
+		return operationResults, fmt.Errorf("unable to list the atoms in namespace %s: %w", namespace, err)
+	}
+
+	var atoms []pdoknlv3.Atom
+	configMaps := make(map[string]corev1.ConfigMap)
+	for _, atom := range atomList.Items {
+		if !atom.DeletionTimestamp.IsZero() {
+			continue
+		}
+		configMap := getBareConfigMap(&atom)
+		if err = c.Get(ctx, client.ObjectKeyFromObject(configMap), configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				// The feeds of this Atom are not rendered yet, it adds itself when it is reconciled
+				continue
+			}
+			return operationResults, fmt.Errorf("unable to get resource %s: %w", smoothutil.GetObjectFullName(c, configMap), err)
+		}
+		atoms = append(atoms, atom)
+		configMaps[atom.Name] = *configMap
+	}
+
+	deployment := getBareSharedServerDeployment(namespace)
+	service := getBareSharedServerService(namespace)
+	podDisruptionBudget := getBareSharedServerPodDisruptionBudget(namespace)
+
+	if len(atoms) == 0 {
+		for _, obj := range []client.Object{deployment, service, podDisruptionBudget} {
+			if err = r.deleteIfExists(ctx, obj); err != nil {
+				return operationResults, err
+			}
+		}
+		return operationResults, nil
+	}
+
+	// Every Atom in the namespace updates the shared resources, so an update can conflict with the reconcile of another Atom
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		operationResults[smoothutil.GetObjectFullName(c, deployment)], err = controllerutil.CreateOrUpdate(ctx, c, deployment, func() error {
+			currentImages := getContainerImages(&deployment.Spec.Template.Spec)
+			if err := r.mutateSharedServerDeployment(atoms, configMaps, settings, deployment); err != nil {
+				return err
+			}
+			if r.ImageRollout == nil {
+				return nil
+			}
+			return r.throttleImageRollout(ctx, nil, currentImages, deployment)
+		})
+		return err
+	})
+	if err != nil {
+		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, deployment), err)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		operationResults[smoothutil.GetObjectFullName(c, service)], err = controllerutil.CreateOrUpdate(ctx, c, service, func() error {
+			return r.mutateSharedServerService(atoms, service)
+		})
+		return err
+	})
+	if err != nil {
+		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, service), err)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		operationResults[smoothutil.GetObjectFullName(c, podDisruptionBudget)], err = controllerutil.CreateOrUpdate(ctx, c, podDisruptionBudget, func() error {
+			return r.mutateSharedServerPodDisruptionBudget(atoms, podDisruptionBudget)
+		})
+		return err
+	})
+	if err != nil {
+		return operationResults, fmt.Errorf("unable to create/update resource %s: %w", smoothutil.GetObjectFullName(c, podDisruptionBudget), err)
+	}
+
+	return operationResults, nil
+}
+
+// mutateSharedServerDeployment mounts the feeds of every Atom in a directory named after the Atom
+func (r *AtomReconciler) mutateSharedServerDeployment(atoms []pdoknlv3.Atom, configMaps map[string]corev1.ConfigMap, settings pdoknlv3.OperatorSettings, deployment *appsv1.Deployment) error {
+	deployment.Labels = smoothutil.CombineLabels(deployment.Labels, sharedServerLabels)
+
+	podTemplateAnnotations := smoothutil.CloneOrEmptyMap(deployment.Spec.Template.GetAnnotations())
+	podTemplateAnnotations[evictAnnotation] = evictValue
+	podTemplateAnnotations[defaultContainerAnnotation] = "atom-service"
+	podTemplateAnnotations[versionCheckerAnnotation] = versionCheckerPriority
+
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: sharedServerLabels}
+	setDeploymentRollout(deployment)
+
+	var sources []corev1.VolumeProjection
+	for _, atom := range sortedByName(atoms) {
+		configMap := configMaps[atom.Name]
+		fileNames := make([]string, 0, len(configMap.Data))
+		for fileName := range configMap.Data {
+			fileNames = append(fileNames, fileName)
+		}
+		slices.Sort(fileNames)
+
+		items := make([]corev1.KeyToPath, 0, len(fileNames))
+		for _, fileName := range fileNames {
+			items = append(items, corev1.KeyToPath{Key: fileName, Path: atom.Name + "/" + fileName})
+		}
+		// Optional, because the ConfigMap is deleted when the Atom is no longer served by the shared server
+		sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMap.Name},
+			Items:                items,
+			Optional:             smoothutil.Pointer(true),
+		}})
+	}
+
+	// There is no feed at the root, so the probes only check that lighttpd is listening
+	container := getAtomServiceContainer(settings.LighttpdImage, corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(atomPortNr)},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "feeds", MountPath: "/var/www/", ReadOnly: true})
+
+	deployment.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      smoothutil.CombineLabels(deployment.Spec.Template.Labels, sharedServerLabels),
+			Annotations: podTemplateAnnotations,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "socket", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "feeds", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}}},
+			},
+			Containers: []corev1.Container{container},
+		},
+	}
+
+	if err := smoothutil.EnsureSetGVK(r.Client, deployment, deployment); err != nil {
+		return err
+	}
+	return r.setSharedOwnerReferences(atoms, deployment)
+}
+
+func (r *AtomReconciler) mutateSharedServerService(atoms []pdoknlv3.Atom, service *corev1.Service) error {
+	service.Labels = smoothutil.CombineLabels(service.Labels, sharedServerLabels)
+
+	service.Spec = corev1.ServiceSpec{
+		Ports: []corev1.ServicePort{
+			{
+				Name:     atomPortName,
+				Port:     atomPortNr,
+				Protocol: corev1.ProtocolTCP,
+			},
+		},
+		Selector: sharedServerLabels,
+	}
+	if err := smoothutil.EnsureSetGVK(r.Client, service, service); err != nil {
+		return err
+	}
+	return r.setSharedOwnerReferences(atoms, service)
+}
+
+func (r *AtomReconciler) mutateSharedServerPodDisruptionBudget(atoms []pdoknlv3.Atom, podDisruptionBudget *policyv1.PodDisruptionBudget) error {
+	podDisruptionBudget.Labels = smoothutil.CombineLabels(podDisruptionBudget.Labels, sharedServerLabels)
+
+	podDisruptionBudget.Spec = policyv1.PodDisruptionBudgetSpec{
+		MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		Selector:       &metav1.LabelSelector{MatchLabels: sharedServerLabels},
+	}
+
+	if err := smoothutil.EnsureSetGVK(r.Client, podDisruptionBudget, podDisruptionBudget); err != nil {
+		return err
+	}
+	return r.setSharedOwnerReferences(atoms, podDisruptionBudget)
+}
+
+// setSharedOwnerReferences makes all Atoms owner, so the shared resources are only garbage collected after the last Atom is deleted
+func (r *AtomReconciler) setSharedOwnerReferences(atoms []pdoknlv3.Atom, obj client.Object) error {
+	obj.SetOwnerReferences(nil)
+	for _, atom := range sortedByName(atoms) {
+		if err := controllerutil.SetOwnerReference(&atom, obj, r.Scheme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePerAtomServer removes the Deployment, Service and PodDisruptionBudget of an Atom that is served by the shared server
+func (r *AtomReconciler) deletePerAtomServer(ctx context.Context, atom *pdoknlv3.Atom) error {
+	for _, obj := range []client.Object{getBareDeployment(atom), getBareService(atom), getBarePodDisruptionBudget(atom)} {
+		if err := r.deleteIfExists(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseSharedServer removes the Atom from the owners of the shared server of its namespace, after the shared server was turned off.
+// The shared server keeps serving the Atoms that are not reconciled yet, it is deleted when the last Atom released it.
+// The ConfigMap with the feeds of the Atom for the shared server is deleted as well.
+func (r *AtomReconciler) releaseSharedServer(ctx context.Context, atom *pdoknlv3.Atom) error {
+	for _, obj := range []client.Object{
+		getBareSharedServerDeployment(atom.Namespace),
+		getBareSharedServerService(atom.Namespace),
+		getBareSharedServerPodDisruptionBudget(atom.Namespace),
+	} {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("unable to get resource %s: %w", smoothutil.GetObjectFullName(r.Client, obj), err)
+		}
+
+		ownerReferences := slices.DeleteFunc(slices.Clone(obj.GetOwnerReferences()), func(ownerReference metav1.OwnerReference) bool {
+			return ownerReference.UID == atom.UID
+		})
+		switch {
+		case len(ownerReferences) == len(obj.GetOwnerReferences()):
+			continue
+		case len(ownerReferences) == 0:
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("unable to delete resource %s: %w", smoothutil.GetObjectFullName(r.Client, obj), err)
+			}
+		default:
+			obj.SetOwnerReferences(ownerReferences)
+			if err := r.Update(ctx, obj); err != nil {
+				return fmt.Errorf("unable to update resource %s: %w", smoothutil.GetObjectFullName(r.Client, obj), err)
+			}
+		}
+	}
+	return r.deleteIfExists(ctx, getBareConfigMap(atom))
+}
+
+// deleteIfExists only deletes the object when it is found in the cache, so a reconcile does not delete objects that are long gone
+func (r *AtomReconciler) deleteIfExists(ctx context.Context, obj client.Object) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get resource %s: %w", smoothutil.GetObjectFullName(r.Client, obj), err)
+	}
+	if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("unable to delete resource %s: %w", smoothutil.GetObjectFullName(r.Client, obj), err)
+	}
+	return nil
+}
+
+func sortedByName(atoms []pdoknlv3.Atom) []pdoknlv3.Atom {
+	return slices.SortedFunc(slices.Values(atoms), func(a, b pdoknlv3.Atom) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
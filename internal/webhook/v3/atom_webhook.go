@@ -30,13 +30,19 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	smoothoperatorv1 "github.com/pdok/smooth-operator/api/v1"
+	smoothoperatorvalidation "github.com/pdok/smooth-operator/pkg/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
 	pdoknlv3 "github.com/pdok/atom-operator/api/v3"
+	"github.com/pdok/atom-operator/internal/controller/generator"
 )
 
 // log is for logging in this package.
@@ -45,12 +51,65 @@ import (
 var atomlog = logf.Log.WithName("atom-resource")
 
 // SetupAtomWebhookWithManager registers the webhook for Atom in the manager.
-func SetupAtomWebhookWithManager(mgr ctrl.Manager) error {
+// With legacyAtomGenerator the validator warns about the parts of Atoms that the atom-generator cannot render,
+// otherwise it warns about Atoms whose rendered feeds do not fit in the ConfigMap they are served from, unless the feeds are published.
+// Image overrides that have no effect with the legacyAtomGenerator, sharedServer or publishedFeeds are warned about as well.
+// The base URL and allowed image registries are read with the settings, on every request.
+func SetupAtomWebhookWithManager(mgr ctrl.Manager, settings *pdoknlv3.SettingsReader, legacyAtomGenerator, sharedServer, publishedFeeds bool) error {
+	// Index the URLs of Atoms, so URL collisions can be looked up
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &pdoknlv3.Atom{}, pdoknlv3.URLIndexKey, pdoknlv3.IndexURLs); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &pdoknlv3.Atom{}, pdoknlv3.URLPrefixIndexKey, pdoknlv3.IndexURLPrefixes); err != nil {
+		return err
+	}
+
 	return ctrl.NewWebhookManagedBy(mgr).For(&pdoknlv3.Atom{}).
-		WithValidator(&AtomCustomValidator{mgr.GetClient()}).
+		WithValidator(&AtomCustomValidator{
+			Client:              mgr.GetClient(),
+			Settings:            settings,
+			LegacyAtomGenerator: legacyAtomGenerator,
+			SharedServer:        sharedServer,
+			PublishedFeeds:      publishedFeeds,
+			ConfigMapFeeds:      !legacyAtomGenerator && !publishedFeeds,
+		}).
+		WithDefaulter(&AtomCustomDefaulter{Client: mgr.GetClient(), Settings: settings}).
 		Complete()
 }
 
+// +kubebuilder:webhook:path=/mutate-pdok-nl-v3-atom,mutating=true,failurePolicy=fail,sideEffects=None,groups=pdok.nl,resources=atoms,verbs=create;update,versions=v3,name=matom-v3.kb.io,admissionReviewVersions=v1
+
+// AtomCustomDefaulter struct is responsible for setting default values on the Atom resource
+// when it is created or updated.
+type AtomCustomDefaulter struct {
+	Client   client.Client
+	Settings *pdoknlv3.SettingsReader
+}
+
+var _ webhook.CustomDefaulter = &AtomCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type Atom.
+func (d *AtomCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	atom, ok := obj.(*pdoknlv3.Atom)
+	if !ok {
+		return fmt.Errorf("expected an Atom object but got %T", obj)
+	}
+	atomlog.Info("Defaulting for Atom", "name", atom.GetName())
+
+	// URLs of existing Atoms are immutable, so only normalise them upon creation
+	normaliseURLs := true
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		normaliseURLs = req.Operation == admissionv1.Create
+	}
+
+	settings, err := d.Settings.GetSettings(ctx, atom.Namespace)
+	if err != nil {
+		return err
+	}
+	atom.Default(d.Client, settings.BaseURL, normaliseURLs)
+	return nil
+}
+
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
 // +kubebuilder:webhook:path=/validate-pdok-nl-v3-atom,mutating=false,failurePolicy=fail,sideEffects=None,groups=pdok.nl,resources=atoms,verbs=create;update,versions=v3,name=vatom-v3.kb.io,admissionReviewVersions=v1
@@ -61,24 +120,37 @@ func SetupAtomWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type AtomCustomValidator struct {
-	Client client.Client
+	Client              client.Client
+	Settings            *pdoknlv3.SettingsReader
+	LegacyAtomGenerator bool
+	// SharedServer is set when the Atoms in a namespace are served by one shared Deployment
+	SharedServer bool
+	// PublishedFeeds is set when the feeds are published to blob storage instead of being served by pods
+	PublishedFeeds bool
+	// ConfigMapFeeds is set when the rendered feeds are served from a ConfigMap, which limits their size
+	ConfigMapFeeds bool
 }
 
 var _ webhook.CustomValidator = &AtomCustomValidator{}
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Atom.
-func (v *AtomCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (v *AtomCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	atom, ok := obj.(*pdoknlv3.Atom)
 	if !ok {
 		return nil, fmt.Errorf("expected a Atom object but got %T", obj)
 	}
 	atomlog.Info("Validation for Atom upon creation", "name", atom.GetName())
 
-	return atom.ValidateCreate(v.Client)
+	settings, err := v.Settings.GetSettings(ctx, atom.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := atom.ValidateCreate(v.Client, settings.AllowedImageRegistries)
+	return v.addWarnings(ctx, atom, warnings), err
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Atom.
-func (v *AtomCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+func (v *AtomCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
 	atomlog.Info("reading newAtom")
 	atom, ok := newObj.(*pdoknlv3.Atom)
 	if !ok {
@@ -91,7 +163,48 @@ func (v *AtomCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj r
 	}
 	atomlog.Info("Validation for Atom upon update", "name", atom.GetName())
 
-	return atom.ValidateUpdate(v.Client, atomOld)
+	settings, err := v.Settings.GetSettings(ctx, atom.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := atom.ValidateUpdate(v.Client, settings.AllowedImageRegistries, atomOld)
+	return v.addWarnings(ctx, atom, warnings), err
+}
+
+func (v *AtomCustomValidator) addWarnings(ctx context.Context, atom *pdoknlv3.Atom, warnings []string) admission.Warnings {
+	if v.LegacyAtomGenerator {
+		pdoknlv3.AddLegacyAtomGeneratorWarnings(atom, &warnings)
+	}
+	pdoknlv3.AddIgnoredImageWarnings(atom, v.LegacyAtomGenerator, v.SharedServer, v.PublishedFeeds, &warnings)
+	if v.ConfigMapFeeds {
+		v.addConfigMapSizeWarning(ctx, atom, &warnings)
+	}
+	return warnings
+}
+
+// addConfigMapSizeWarning renders the feeds of the Atom, without requesting the downloads, to warn when they do not fit in a ConfigMap.
+// Atoms that cannot be rendered are not warned about, validation reports why.
+func (v *AtomCustomValidator) addConfigMapSizeWarning(ctx context.Context, atom *pdoknlv3.Atom, warnings *[]string) {
+	ownerInfo := &smoothoperatorv1.OwnerInfo{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Namespace: atom.Namespace, Name: atom.Spec.Service.OwnerInfoRef}, ownerInfo); err != nil {
+		return
+	}
+	// The blob endpoint is not needed, because the downloads are not requested
+	atomGeneratorConfig, err := generator.MapAtomV3ToAtomGeneratorConfig(*atom, *ownerInfo, "")
+	if err != nil {
+		return
+	}
+	options := generator.GetRenderOptions(*atom)
+	options.SkipLinkData = true
+	rendered, err := generator.RenderFeeds(atomGeneratorConfig, options, nil)
+	if err != nil {
+		return
+	}
+
+	if size := generator.GetRenderedSize(rendered); size > generator.MaxConfigMapSize {
+		message := fmt.Sprintf("renders to %d bytes of feeds, which does not fit in the ConfigMap of at most %d bytes the feeds are served from", size, generator.MaxConfigMapSize)
+		smoothoperatorvalidation.AddWarning(warnings, *field.NewPath("spec").Child("service").Child("datasetFeeds"), message, atom.GroupVersionKind(), atom.GetName())
+	}
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Atom.
@@ -27,14 +27,20 @@ package v3
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	v1 "github.com/pdok/smooth-operator/api/v1"
 	"github.com/pdok/smooth-operator/model"
+	smoothutil "github.com/pdok/smooth-operator/pkg/util"
+	admissionv1 "k8s.io/api/admission/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo bdd
@@ -205,27 +211,572 @@ var _ = Describe("Atom Webhook", func() {
 			)
 		})
 
+		It("Should deny creation if a page or archive of a datasetfeed has the file name of another datasetfeed", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					datasetFeed := &atom.Spec.Service.DatasetFeeds[0]
+					datasetFeed.PageSize = smoothutil.Pointer(int32(1))
+					datasetFeed.Archive = &pdoknlv3.Archive{}
+					secondEntry := datasetFeed.Entries[0]
+					secondEntry.TechnicalName += "-2"
+					datasetFeed.Entries = append(datasetFeed.Entries, secondEntry)
+
+					page := atom.Spec.Service.DatasetFeeds[0]
+					page.TechnicalName += "-2"
+					page.PageSize, page.Archive = nil, nil
+					archive := page
+					archive.TechnicalName = datasetFeed.TechnicalName + "-archive"
+					index := page
+					index.TechnicalName = "index"
+					atom.Spec.Service.DatasetFeeds = append(atom.Spec.Service.DatasetFeeds, page, archive, index)
+				},
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[1].technicalName"),
+							atom.Spec.Service.DatasetFeeds[1].TechnicalName,
+							fmt.Sprintf("results in file %s.xml, which is also a file of spec.service.datasetFeeds[0]", atom.Spec.Service.DatasetFeeds[1].TechnicalName),
+						),
+						field.Invalid(
+							servicePath.Child("datasetFeeds[2].technicalName"),
+							atom.Spec.Service.DatasetFeeds[2].TechnicalName,
+							fmt.Sprintf("results in file %s.xml, which is also a file of spec.service.datasetFeeds[0]", atom.Spec.Service.DatasetFeeds[2].TechnicalName),
+						),
+						field.Invalid(
+							servicePath.Child("datasetFeeds[3].technicalName"),
+							"index",
+							"results in file index.xml, which is also a file of spec.service",
+						),
+					}, nil
+				},
+			)
+		})
+
 		It("Should deny creation if a datasetfeed entries have duplicate technical names", func() {
 			testCreate(
 				validator,
 				"minimal.yaml",
 				func(atom *pdoknlv3.Atom) {
-					atom.Spec.Service.DatasetFeeds[0].Entries = append(
-						atom.Spec.Service.DatasetFeeds[0].Entries,
-						atom.Spec.Service.DatasetFeeds[0].Entries...,
-					)
+					atom.Spec.Service.DatasetFeeds[0].Entries = append(
+						atom.Spec.Service.DatasetFeeds[0].Entries,
+						atom.Spec.Service.DatasetFeeds[0].Entries...,
+					)
+				},
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Duplicate(
+							servicePath.Child("datasetFeeds[0].entries[0].entries[1].technicalName"),
+							atom.Spec.Service.DatasetFeeds[0].Entries[0].TechnicalName,
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if a polygon bbox has a minimum larger than its maximum", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					bbox := &atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.BBox
+					bbox.MinX, bbox.MaxX = bbox.MaxX, bbox.MinX
+				},
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					bbox := atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.BBox
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[0].entries[0].polygon.bbox.minx"),
+							bbox.MinX,
+							"should not be larger than maxx "+bbox.MaxX,
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if a polygon bbox is outside the area of the srs", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					srsURI, _ := model.ParseURL("http://www.opengis.net/def/crs/EPSG/0/4326")
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].SRS = pdoknlv3.SRS{URI: model.URL{URL: srsURI}, Name: "WGS 84"}
+				},
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[0].entries[0].polygon.bbox"),
+							atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.BBox.ToExtent(),
+							"is outside the area of EPSG:4326 from spec.service.datasetFeeds[0].entries[0].srs.uri",
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if a downloadlink bbox is not a number", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0].BBox = &model.BBox{
+						MinX: "100000", MinY: "400000", MaxX: "a", MaxY: "500000",
+					}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("datasetFeeds[0].entries[0].downloadlinks[0].bbox.maxx"), "a", "must be a number"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should create atom but warn about a downloadlink bbox outside the polygon of the entry", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.BBox = model.BBox{
+						MinX: "100000", MinY: "400000", MaxX: "200000", MaxY: "500000",
+					}
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0].BBox = &model.BBox{
+						MinX: "150000", MinY: "450000", MaxX: "250000", MaxY: "500000",
+					}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds[0].entries[0].downloadlinks[0].bbox: falls outside spec.service.datasetFeeds[0].entries[0].polygon.bbox",
+					}
+				},
+			)
+		})
+
+		It("Should create atom with a polygon geometry instead of a bbox", func() {
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				geometry := `{"type": "MultiPolygon", "coordinates": [[[[100000, 400000], [200000, 400000], [100000, 500000], [100000, 400000]]]]}`
+				atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon = pdoknlv3.Polygon{Geometry: &geometry}
+			}, nil)
+		})
+
+		It("Should deny creation if a polygon geometry is not closed", func() {
+			geometry := "POLYGON ((100000 400000, 200000 400000, 100000 500000, 100000 450000))"
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.Geometry = &geometry
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[0].entries[0].polygon.geometry"),
+							geometry,
+							"ring 1 of polygon 1 is not closed, the first and last position should be equal",
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if the exterior ring of a polygon geometry is clockwise", func() {
+			geometry := "POLYGON ((100000 400000, 100000 500000, 200000 400000, 100000 400000))"
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.Geometry = &geometry
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[0].entries[0].polygon.geometry"),
+							geometry,
+							"ring 1 of polygon 1 is an exterior ring and should be counterclockwise",
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should create atom but warn about a polygon geometry outside the polygon bbox", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					geometry := "POLYGON ((100000 400000, 300000 400000, 100000 500000, 100000 400000))"
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon = pdoknlv3.Polygon{
+						BBox:     model.BBox{MinX: "100000", MinY: "400000", MaxX: "200000", MaxY: "500000"},
+						Geometry: &geometry,
+					}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds[0].entries[0].polygon.geometry: falls outside spec.service.datasetFeeds[0].entries[0].polygon.bbox",
+					}
+				},
+			)
+		})
+
+		It("Should create atom but warn that the legacy atom-generator reduces a multipolygon geometry to its bbox", func() {
+			validator.LegacyAtomGenerator = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					geometry := "MULTIPOLYGON (((100000 400000, 150000 400000, 100000 450000, 100000 400000)), " +
+						"((150000 450000, 200000 450000, 150000 500000, 150000 450000)))"
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon = pdoknlv3.Polygon{Geometry: &geometry}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds[0].entries[0].polygon.geometry: is reduced to its bbox, the legacy atom-generator only supports georss:polygon",
+					}
+				},
+			)
+		})
+
+		It("Should create atom but warn that the legacy atom-generator drops keywords and INSPIRE themes", func() {
+			validator.LegacyAtomGenerator = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					theme, err := model.ParseURL("http://inspire.ec.europa.eu/theme/au")
+					Expect(err).NotTo(HaveOccurred())
+					atom.Spec.Service.Keywords = []string{"downloads"}
+					atom.Spec.Service.DatasetFeeds[0].InspireThemes = []pdoknlv3.InspireTheme{{URI: model.URL{URL: theme}}}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.service.keywords: are not published, the legacy atom-generator does not support categories of feeds",
+						"pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds[0].inspireThemes: are not published, the legacy atom-generator does not support categories of feeds",
+					}
+				},
+			)
+		})
+
+		It("Should create atom but warn that the legacy atom-generator ignores the refresh", func() {
+			validator.LegacyAtomGenerator = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Refresh = &pdoknlv3.Refresh{Interval: metav1.Duration{Duration: time.Hour}}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.refresh: is ignored, the legacy atom-generator only renders the feeds when a pod starts",
+					}
+				},
+			)
+		})
+
+		It("Should deny creation if the refresh interval is shorter than a minute", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Refresh = &pdoknlv3.Refresh{Interval: metav1.Duration{Duration: time.Second}}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(field.NewPath("spec").Child("refresh").Child("interval"), "1s", "should be at least 1m0s"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should create atom but warn when the feeds do not fit in the ConfigMap they are served from", func() {
+			validator.ConfigMapFeeds = true
+			atom := testCreate(validator, "minimal.yaml", nil, nil)
+			atom.Spec.Service.DatasetFeeds[0].Entries[0].Content = smoothutil.Pointer(strings.Repeat("content ", 128*1024))
+			warnings, err := validator.ValidateCreate(ctx, atom)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(HavePrefix("pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds: renders to "))
+			Expect(warnings[0]).To(HaveSuffix("which does not fit in the ConfigMap of at most 1024000 bytes the feeds are served from"))
+		})
+
+		It("Should create atom with service links", func() {
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				endpoint, err := model.ParseURL("https://service.pdok.nl/owner/dataset/wfs/v1_0")
+				Expect(err).NotTo(HaveOccurred())
+				atom.Spec.Service.DatasetFeeds[0].ServiceLinks = []pdoknlv3.ServiceLink{
+					{Protocol: "wfs", Endpoint: model.URL{URL: endpoint}, Layer: "layer"},
+				}
+			}, nil)
+		})
+
+		It("Should deny creation if a service link endpoint is not absolute", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					endpoint, err := url.Parse("http:///owner/dataset/wcs")
+					Expect(err).NotTo(HaveOccurred())
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].ServiceLinks = []pdoknlv3.ServiceLink{
+						{Protocol: "wcs", Endpoint: model.URL{URL: endpoint}, Layer: "coverage"},
+					}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("datasetFeeds[0].entries[0].serviceLinks[0].endpoint"), "http:///owner/dataset/wcs", "must be an absolute URL"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if an updated time is in the future", func() {
+			future := metav1.NewTime(time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC))
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Updated = &future
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Updated = future
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("datasetFeeds[0].updated"), "2999-01-01T00:00:00Z", "should not be in the future"),
+						field.Invalid(servicePath.Child("datasetFeeds[0].entries[0].updated"), "2999-01-01T00:00:00Z", "should not be in the future"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if an INSPIRE theme is not in the register or repeated", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					au, err := url.Parse("http://inspire.ec.europa.eu/theme/au")
+					Expect(err).NotTo(HaveOccurred())
+					unknown, err := url.Parse("http://inspire.ec.europa.eu/theme/xx")
+					Expect(err).NotTo(HaveOccurred())
+					atom.Spec.Service.InspireThemes = []pdoknlv3.InspireTheme{{URI: model.URL{URL: unknown}}}
+					atom.Spec.Service.DatasetFeeds[0].InspireThemes = []pdoknlv3.InspireTheme{{URI: model.URL{URL: au}}, {URI: model.URL{URL: au}}}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("inspireThemes[0].uri"), "http://inspire.ec.europa.eu/theme/xx",
+							"should be a theme of the INSPIRE theme register, for example http://inspire.ec.europa.eu/theme/au"),
+						field.Duplicate(servicePath.Child("datasetFeeds[0].inspireThemes[1].uri"), "http://inspire.ec.europa.eu/theme/au"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if an entry is archived but the dataset feed has no archive", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Archived = true
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("datasetFeeds[0].entries[0].archived"), true, "requires spec.service.datasetFeeds[0].archive"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if all entries are archived and the dataset feed has no updated", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Archive = &pdoknlv3.Archive{}
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].Archived = true
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Required(servicePath.Child("datasetFeeds[0].updated"), "when all entries are archived"),
+					}, nil
+				},
+			)
+
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Service.DatasetFeeds[0].Archive = &pdoknlv3.Archive{}
+				atom.Spec.Service.DatasetFeeds[0].Entries[0].Archived = true
+				atom.Spec.Service.DatasetFeeds[0].Updated = &atom.Spec.Service.DatasetFeeds[0].Entries[0].Updated
+			}, nil)
+		})
+
+		It("Should update atom but warn about an entry moved to the archive without a time", func() {
+			atomOld := testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Service.DatasetFeeds[0].Archive = &pdoknlv3.Archive{}
+			}, nil)
+
+			atomNew := atomOld.DeepCopy()
+			atomNew.Spec.Service.DatasetFeeds[0].Entries[0].Archived = true
+			atomNew.Spec.Service.DatasetFeeds[0].Updated = &atomNew.Spec.Service.DatasetFeeds[0].Entries[0].Updated
+
+			warnings, err := validator.ValidateUpdate(ctx, atomOld, atomNew)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(Equal(admission.Warnings{
+				"pdok.nl/v3, Kind=Atom/minimal: spec.service.datasetFeeds[0].entries[0].archived: entry is moved to the archive without a time on its downloadlinks",
+			}))
+
+			By("not warning when the downloadlinks have a time")
+			atomNew.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0].Time = smoothutil.Pointer("2025-01-01T00:00:00Z")
+			warnings, err = validator.ValidateUpdate(ctx, atomOld, atomNew)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should create atom with a type and length on a downloadlink", func() {
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				downloadLink := &atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0]
+				downloadLink.Type = smoothutil.Pointer("application/vnd.ogc.gpkg+sqlite3")
+				downloadLink.Length = smoothutil.Pointer(int64(1024))
+			}, nil)
+		})
+
+		It("Should deny creation if the type of a downloadlink is not a valid media type", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0].Type = smoothutil.Pointer("text/plain; charset")
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(
+							servicePath.Child("datasetFeeds[0].entries[0].downloadlinks[0].type"),
+							"text/plain; charset",
+							"must be a valid media type, for example application/geopackage+sqlite3",
+						),
+					}, nil
+				},
+			)
+		})
+
+		It("Should create atom with images from an allowed registry", func() {
+			validator.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}}}
+
+			validator.LegacyAtomGenerator = true
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Images = &pdoknlv3.Images{
+					AtomGenerator: smoothutil.Pointer("registry.test/pdok/atom-generator:canary"),
+					Lighttpd:      smoothutil.Pointer("registry.test/pdok/lighttpd@sha256:0123"),
+				}
+			}, nil)
+		})
+
+		It("Should create atom but warn that the shared server ignores the lighttpd image", func() {
+			validator.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}}}
+
+			validator.SharedServer = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Images = &pdoknlv3.Images{Lighttpd: smoothutil.Pointer("registry.test/pdok/lighttpd:canary")}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.images.lighttpd: is ignored, the shared server of the namespace runs the lighttpd image of the operator",
+					}
+				},
+			)
+		})
+
+		It("Should create atom but warn that the atom-generator image is ignored without the legacy atom-generator", func() {
+			validator.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}}}
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Images = &pdoknlv3.Images{AtomGenerator: smoothutil.Pointer("registry.test/pdok/atom-generator:canary")}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.images.atomGenerator: is ignored, the feeds are rendered by the operator instead of by the atom-generator",
+					}
+				},
+			)
+		})
+
+		It("Should create atom but warn that the lighttpd image is ignored for published feeds", func() {
+			validator.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}}}
+
+			validator.PublishedFeeds = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Images = &pdoknlv3.Images{Lighttpd: smoothutil.Pointer("registry.test/pdok/lighttpd:canary")}
 				},
-				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return nil, admission.Warnings{
+						"pdok.nl/v3, Kind=Atom/minimal: spec.images.lighttpd: is ignored, the published feeds are served from blob storage",
+					}
+				},
+			)
+		})
+
+		It("Should deny creation if an image is not from an allowed registry", func() {
+			validator.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}}}
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Images = &pdoknlv3.Images{Lighttpd: smoothutil.Pointer("registry.test/pdok-other/lighttpd:canary")}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
 					return field.ErrorList{
-						field.Duplicate(
-							servicePath.Child("datasetFeeds[0].entries[0].entries[1].technicalName"),
-							atom.Spec.Service.DatasetFeeds[0].Entries[0].TechnicalName,
+						field.Invalid(
+							field.NewPath("spec").Child("images").Child("lighttpd"),
+							"registry.test/pdok-other/lighttpd:canary",
+							"should be in one of the allowed registries: registry.test/pdok",
 						),
 					}, nil
 				},
 			)
 		})
 
+		It("Should create atom with images from a registry allowed for its namespace by the operator config", func() {
+			operatorConfig := &pdoknlv3.AtomOperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "atom-operator"},
+				Spec: pdoknlv3.AtomOperatorConfigSpec{
+					AtomOperatorSettings: pdoknlv3.AtomOperatorSettings{AllowedImageRegistries: []string{"registry.test/other"}},
+					NamespaceOverrides: []pdoknlv3.NamespaceOverride{{
+						Namespace:            "services",
+						AtomOperatorSettings: pdoknlv3.AtomOperatorSettings{AllowedImageRegistries: []string{"registry.test/pdok"}},
+					}},
+				},
+			}
+			validator.Settings = &pdoknlv3.SettingsReader{
+				Reader:     fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(operatorConfig).Build(),
+				ConfigName: "atom-operator",
+			}
+
+			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Images = &pdoknlv3.Images{Lighttpd: smoothutil.Pointer("registry.test/pdok/lighttpd:canary")}
+			}, nil)
+		})
+
+		It("Should deny creation if images are overridden but no registries are allowed", func() {
+			validator.LegacyAtomGenerator = true
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Images = &pdoknlv3.Images{AtomGenerator: smoothutil.Pointer("registry.test/pdok/atom-generator:canary")}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Forbidden(field.NewPath("spec").Child("images").Child("atomGenerator"), "no image registries are allowed by the operator"),
+					}, nil
+				},
+			)
+		})
+
 		It("Should create atom with ingressRouteUrls that contains the service baseUrl", func() {
 			testCreate(validator, "minimal.yaml", func(atom *pdoknlv3.Atom) {
 				atom.Spec.IngressRouteURLs = model.IngressRouteURLs{
@@ -330,6 +881,220 @@ var _ = Describe("Atom Webhook", func() {
 			)
 		})
 
+		It("Should deny creation if metadata link templates are not defined in the OwnerInfo or do not apply", func() {
+			ownerRef := "atom-owner-custom-templates"
+			o := v1.OwnerInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ownerRef,
+					Namespace: "services",
+					Annotations: map[string]string{pdoknlv3.MetadataLinkTemplatesAnnotation: `[{"name": "iso", "rel": "describedby", ` +
+						`"type": "application/xml", "hrefTemplate": "https://catalogue.test/{{identifier}}.xml", "contexts": ["datasetFeed"]}]`},
+				},
+				Spec: v1.OwnerInfoSpec{
+					Atom: &v1.Atom{Author: model.Author{Name: "atom", Email: "atom@example.com"}},
+					MetadataUrls: &v1.MetadataUrls{
+						CSW:        &v1.MetadataURL{HrefTemplate: "https://csw.test?id={{identifier}}"},
+						OpenSearch: &v1.MetadataURL{HrefTemplate: "https://opensearch.test/{{identifier}}"},
+						HTML:       &v1.MetadataURL{HrefTemplate: "https://html.test/{{identifier}}"},
+					},
+				},
+			}
+			Expect(validator.Client.Create(context.TODO(), &o)).To(Succeed())
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.OwnerInfoRef = ownerRef
+					atom.Spec.Service.ServiceMetadataLinks.Templates = append(atom.Spec.Service.ServiceMetadataLinks.Templates, "iso")
+					atom.Spec.Service.DatasetFeeds[0].DatasetMetadataLinks.Templates = []string{"csw", "iso", "missing"}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("serviceMetadataLinks", "templates").Index(3), "iso", "template does not apply to service"),
+						field.NotFound(servicePath.Child("datasetFeeds").Index(0).Child("datasetMetadataLinks", "templates").Index(2), "missing"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if templated hrefs use unknown variables", func() {
+			ownerRef := "atom-owner-href-variables"
+			o := v1.OwnerInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ownerRef,
+					Namespace: "services",
+				},
+				Spec: v1.OwnerInfoSpec{
+					Atom: &v1.Atom{Author: model.Author{Name: "atom", Email: "atom@example.com"}},
+					MetadataUrls: &v1.MetadataUrls{
+						CSW:        &v1.MetadataURL{HrefTemplate: "https://csw.test/{{lang}}?id={{identifier}}&dataset={{technicalName}}"},
+						OpenSearch: &v1.MetadataURL{HrefTemplate: "https://opensearch.test/{{identifier}}"},
+						HTML:       &v1.MetadataURL{HrefTemplate: "https://html.test/{{identifier}}"},
+					},
+				},
+			}
+			Expect(validator.Client.Create(context.TODO(), &o)).To(Succeed())
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					langHref, err := url.Parse("https://test.com/{{lang}}/{{technicalName}}")
+					Expect(err).NotTo(HaveOccurred())
+					unknownHref, err := url.Parse("https://test.com/{{language}}")
+					Expect(err).NotTo(HaveOccurred())
+					atom.Spec.Service.OwnerInfoRef = ownerRef
+					atom.Spec.Service.DatasetFeeds[0].Links = []pdoknlv3.Link{
+						{Href: model.URL{URL: langHref}, Rel: "related", Type: "text/html"},
+						{Href: model.URL{URL: unknownHref}, Rel: "related", Type: "text/html"},
+					}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					datasetFeedPath := servicePath.Child("datasetFeeds").Index(0)
+					variables := "baseUrl, identifier, lang, ownerInfo, spatialDatasetIdentifierCode, spatialDatasetIdentifierNamespace, technicalName"
+					return field.ErrorList{
+						field.Invalid(datasetFeedPath.Child("links").Index(1).Child("href"), "https://test.com/{{language}}",
+							"href template https://test.com/{{language}}: unknown variable language, available are "+variables),
+						field.Invalid(servicePath.Child("serviceMetadataLinks", "templates").Index(0), "csw",
+							"href template https://csw.test/{{lang}}?id={{identifier}}&dataset={{technicalName}}: unknown variable technicalName, "+
+								"available are baseUrl, identifier, lang, ownerInfo"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if the metadata link templates of the OwnerInfo it uses are invalid", func() {
+			ownerRef := "atom-owner-invalid-templates"
+			o := v1.OwnerInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ownerRef,
+					Namespace: "services",
+					Annotations: map[string]string{pdoknlv3.MetadataLinkTemplatesAnnotation: `[{"name": "csw", "rel": "describedby", ` +
+						`"type": "application/xml", "hrefTemplate": "https://csw.test/{{identifier}}", "contexts": ["service"]}, ` +
+						`{"name": "iso", "rel": "describedby", "type": "xml", "hrefTemplate": "{{identifier", "contexts": ["everywhere"]}]`},
+				},
+				Spec: v1.OwnerInfoSpec{
+					Atom: &v1.Atom{Author: model.Author{Name: "atom", Email: "atom@example.com"}},
+					MetadataUrls: &v1.MetadataUrls{
+						CSW:        &v1.MetadataURL{HrefTemplate: "https://csw.test?id={{identifier}}"},
+						OpenSearch: &v1.MetadataURL{HrefTemplate: "https://opensearch.test/{{identifier}}"},
+						HTML:       &v1.MetadataURL{HrefTemplate: "https://html.test/{{identifier}}"},
+					},
+				},
+			}
+			Expect(validator.Client.Create(context.TODO(), &o)).To(Succeed())
+			_, cswErr := pdoknlv3.GetMetadataLinkTemplate(&o, "csw")
+			Expect(cswErr).To(HaveOccurred())
+			Expect(cswErr.Error()).To(ContainSubstring("name csw is already used"))
+			_, isoErr := pdoknlv3.GetMetadataLinkTemplate(&o, "iso")
+			Expect(isoErr).To(HaveOccurred())
+			Expect(isoErr.Error()).To(ContainSubstring("not a valid mustache template"))
+			html, htmlErr := pdoknlv3.GetMetadataLinkTemplate(&o, "html")
+			Expect(htmlErr).NotTo(HaveOccurred())
+			Expect(html).NotTo(BeNil())
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.OwnerInfoRef = ownerRef
+					atom.Spec.Service.DatasetFeeds[0].DatasetMetadataLinks.Templates = []string{"html", "iso"}
+				},
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("serviceMetadataLinks", "templates").Index(0), "csw", cswErr.Error()),
+						field.Invalid(servicePath.Child("datasetFeeds").Index(0).Child("datasetMetadataLinks", "templates").Index(1), "iso", isoErr.Error()),
+					}, nil
+				},
+			)
+		})
+
+		It("Should allow creation if only metadata link templates of the OwnerInfo it does not use are invalid", func() {
+			ownerRef := "atom-owner-unused-invalid-templates"
+			o := v1.OwnerInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ownerRef,
+					Namespace: "services",
+					Annotations: map[string]string{pdoknlv3.MetadataLinkTemplatesAnnotation: `[{"name": "iso", "rel": "describedby", ` +
+						`"type": "application/xml", "hrefTemplate": "https://catalogue.test/{{identifier}}.xml", "contexts": ["service"]}, ` +
+						`{"name": "broken", "type": "xml", "hrefTemplate": "{{identifier", "unknown": true}, ` +
+						`{"name": "twice", "rel": "describedby", "type": "text/html", "hrefTemplate": "https://twice.test", "contexts": ["service"]}, ` +
+						`{"name": "twice", "rel": "describedby", "type": "text/html", "hrefTemplate": "https://twice.test", "contexts": ["service"]}]`},
+				},
+				Spec: v1.OwnerInfoSpec{
+					Atom: &v1.Atom{Author: model.Author{Name: "atom", Email: "atom@example.com"}},
+					MetadataUrls: &v1.MetadataUrls{
+						CSW:        &v1.MetadataURL{HrefTemplate: "https://csw.test?id={{identifier}}"},
+						OpenSearch: &v1.MetadataURL{HrefTemplate: "https://opensearch.test/{{identifier}}"},
+						HTML:       &v1.MetadataURL{HrefTemplate: "https://html.test/{{identifier}}"},
+					},
+				},
+			}
+			Expect(validator.Client.Create(context.TODO(), &o)).To(Succeed())
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.OwnerInfoRef = ownerRef
+					atom.Spec.Service.ServiceMetadataLinks.Templates = append(atom.Spec.Service.ServiceMetadataLinks.Templates, "iso")
+				},
+				nil,
+			)
+		})
+
+		It("Should deny creation if another Atom uses the same baseUrl", func() {
+			createOtherAtom("other", "http://localhost:32788/owner/dataset/atom")
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				nil,
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("baseUrl"), atom.Spec.Service.BaseURL.String(), "is already used by Atom services/other"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if another Atom uses a parent path of the baseUrl", func() {
+			createOtherAtom("other", "http://localhost:32788/owner/dataset")
+
+			testCreate(
+				validator,
+				"minimal.yaml",
+				nil,
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(servicePath.Child("baseUrl"), atom.Spec.Service.BaseURL.String(), "is a sub path of localhost:32788/owner/dataset used by Atom services/other"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should deny creation if another Atom uses a sub path of an ingressRouteUrl", func() {
+			createOtherAtom("other", "http://localhost:32788/other/path/atom")
+
+			testCreate(
+				validator,
+				"ingress-route-urls.yaml",
+				nil,
+				func(atom *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Invalid(field.NewPath("spec").Child("ingressRouteUrls").Index(1).Child("url"), atom.Spec.IngressRouteURLs[1].URL.String(), "is a parent path of a URL used by Atom services/other"),
+					}, nil
+				},
+			)
+		})
+
+		It("Should create atom if another Atom uses a different path on the same host", func() {
+			createOtherAtom("other", "http://localhost:32788/owner/dataset/atom2")
+
+			testCreate(validator, "minimal.yaml", nil, nil)
+		})
+
 		It("Should create and update atom without errors or warnings", func() {
 			testUpdate(
 				validator,
@@ -461,9 +1226,163 @@ var _ = Describe("Atom Webhook", func() {
 				nil,
 			)
 		})
+
+		It("Should deny creation if the baseUrl is missing and cannot be derived", func() {
+			testCreate(
+				validator,
+				"minimal.yaml",
+				func(atom *pdoknlv3.Atom) {
+					atom.Spec.Service.BaseURL = model.URL{}
+				},
+				func(_ *pdoknlv3.Atom) (field.ErrorList, admission.Warnings) {
+					return field.ErrorList{
+						field.Required(servicePath.Child("baseUrl"), "set it, or set the pdok.nl/owner-id and pdok.nl/dataset-id labels to derive it from"),
+					}, nil
+				},
+			)
+		})
+	})
+
+	Context("When creating or updating Atom under Defaulting Webhook", func() {
+		var defaulter AtomCustomDefaulter
+
+		BeforeEach(func() {
+			defaulter = AtomCustomDefaulter{
+				Client: k8sClient,
+			}
+		})
+
+		It("Should derive the baseUrl from the labels", func() {
+			defaulter.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{BaseURL: "http://localhost:32788"}}
+
+			atom := testDefault(defaulter, ctx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Service.BaseURL = model.URL{}
+				atom.Labels["pdok.nl/tag"] = "theme"
+				atom.Labels["pdok.nl/service-version"] = "v1_0"
+			})
+
+			Expect(atom.Spec.Service.BaseURL.String()).To(Equal("http://localhost:32788/owner/dataset/theme/atom/v1_0"))
+		})
+
+		It("Should not derive the baseUrl when it is set", func() {
+			defaulter.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{BaseURL: "http://other.host"}}
+
+			atom := testDefault(defaulter, ctx, "minimal.yaml", nil)
+
+			Expect(atom.Spec.Service.BaseURL.String()).To(Equal("http://localhost:32788/owner/dataset/atom"))
+		})
+
+		It("Should complete the baseUrl of a converted Atom with the base URL of the operator", func() {
+			defaulter.Settings = &pdoknlv3.SettingsReader{Flags: pdoknlv3.OperatorSettings{BaseURL: "http://other.host/"}}
+
+			atom := testDefault(defaulter, ctx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Service.BaseURL = model.URL{URL: &url.URL{Path: "/owner/dataset/theme/atom"}}
+			})
+
+			Expect(atom.Spec.Service.BaseURL.String()).To(Equal("http://other.host/owner/dataset/theme/atom"))
+		})
+
+		It("Should normalise the URLs upon creation", func() {
+			atom := testDefault(defaulter, ctx, "ingress-route-urls.yaml", func(atom *pdoknlv3.Atom) {
+				baseURL, err := model.ParseURL("http://LocalHost:32788/owner//dataset/atom/index.xml")
+				Expect(err).NotTo(HaveOccurred())
+				atom.Spec.Service.BaseURL = model.URL{URL: baseURL}
+				atom.Spec.IngressRouteURLs[0].URL = model.URL{URL: baseURL}
+			})
+
+			Expect(atom.Spec.Service.BaseURL.String()).To(Equal("http://localhost:32788/owner/dataset/atom"))
+			Expect(atom.Spec.IngressRouteURLs[0].URL.String()).To(Equal("http://localhost:32788/owner/dataset/atom"))
+		})
+
+		It("Should not normalise the URLs upon update", func() {
+			updateCtx := admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Update},
+			})
+
+			atom := testDefault(defaulter, updateCtx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				baseURL, err := model.ParseURL("http://localhost:32788/owner//dataset/atom")
+				Expect(err).NotTo(HaveOccurred())
+				atom.Spec.Service.BaseURL = model.URL{URL: baseURL}
+			})
+
+			Expect(atom.Spec.Service.BaseURL.String()).To(Equal("http://localhost:32788/owner//dataset/atom"))
+		})
+
+		It("Should make the rels of downloadlinks explicit", func() {
+			atom := testDefault(defaulter, ctx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				entry := &atom.Spec.Service.DatasetFeeds[0].Entries[0]
+				entry.DownloadLinks = append(entry.DownloadLinks, pdoknlv3.DownloadLink{Data: "public/owner/dataset/other.gpkg"})
+			})
+
+			for _, downloadLink := range atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks {
+				Expect(downloadLink.Rel).To(HaveValue(Equal("section")))
+			}
+
+			atom = testDefault(defaulter, ctx, "minimal.yaml", nil)
+			Expect(atom.Spec.Service.DatasetFeeds[0].Entries[0].DownloadLinks[0].Rel).To(HaveValue(Equal("alternate")))
+		})
+
+		It("Should default the spatialDatasetIdentifierNamespace to the provider site of the owner", func() {
+			ownerRef := "owner-with-provider-site"
+			o := v1.OwnerInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ownerRef,
+					Namespace: "services",
+				},
+				Spec: v1.OwnerInfoSpec{
+					ProviderSite: &v1.ProviderSite{
+						Type: "simple",
+						Href: "https://www.example.com",
+					},
+				},
+			}
+			Expect(defaulter.Client.Create(ctx, &o)).To(Succeed())
+
+			atom := testDefault(defaulter, ctx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				atom.Spec.Service.OwnerInfoRef = ownerRef
+				atom.Spec.Service.DatasetFeeds[0].SpatialDatasetIdentifierNamespace = nil
+			})
+
+			Expect(atom.Spec.Service.DatasetFeeds[0].SpatialDatasetIdentifierNamespace).To(HaveValue(Equal("https://www.example.com")))
+		})
+
+		It("Should derive the polygon bbox from the geometry", func() {
+			atom := testDefault(defaulter, ctx, "minimal.yaml", func(atom *pdoknlv3.Atom) {
+				geometry := "POLYGON ((100000 400000, 200000 400000, 100000 500000.5, 100000 400000))"
+				atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon = pdoknlv3.Polygon{Geometry: &geometry}
+			})
+
+			Expect(atom.Spec.Service.DatasetFeeds[0].Entries[0].Polygon.BBox).To(Equal(model.BBox{
+				MinX: "100000", MinY: "400000", MaxX: "200000", MaxY: "500000.5",
+			}))
+		})
+
+		It("Should create atom without errors or warnings after defaulting", func() {
+			atom := testDefault(defaulter, ctx, "minimal.yaml", nil)
+
+			warnings, err := validator.ValidateCreate(ctx, atom)
+			Expect(warnings).To(BeEmpty())
+			Expect(err).NotTo(HaveOccurred())
+		})
 	})
 })
 
+func testDefault(defaulter AtomCustomDefaulter, ctx context.Context, baseFile string, mutateFn func(atom *pdoknlv3.Atom)) *pdoknlv3.Atom {
+	By("simulating defaulting of an Atom")
+	input, err := os.ReadFile("test_data/" + baseFile)
+	Expect(err).NotTo(HaveOccurred())
+	atom := &pdoknlv3.Atom{}
+	err = yaml.Unmarshal(input, atom)
+	Expect(err).NotTo(HaveOccurred())
+
+	if mutateFn != nil {
+		mutateFn(atom)
+	}
+
+	Expect(defaulter.Default(ctx, atom)).To(Succeed())
+	return atom
+}
+
 func testUpdate(validator AtomCustomValidator, createFile string, updateFn func(atom *pdoknlv3.Atom), errFn func(atomOld, atomNew *pdoknlv3.Atom) field.ErrorList) {
 	atomOld := testCreate(validator, createFile, nil, nil)
 
@@ -514,3 +1433,23 @@ func testCreate(validator AtomCustomValidator, baseFile string, mutateFn func(at
 
 	return atom
 }
+
+func createOtherAtom(name string, baseURL string) {
+	By("creating another Atom in the cluster")
+	input, err := os.ReadFile("test_data/minimal.yaml")
+	Expect(err).NotTo(HaveOccurred())
+	atom := &pdoknlv3.Atom{}
+	err = yaml.Unmarshal(input, atom)
+	Expect(err).NotTo(HaveOccurred())
+
+	atom.Name = name
+	atom.Labels["pdok.nl/dataset-id"] = name
+	url, err := model.ParseURL(baseURL)
+	Expect(err).NotTo(HaveOccurred())
+	atom.Spec.Service.BaseURL = model.URL{URL: url}
+
+	Expect(k8sClient.Create(ctx, atom)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(ctx, atom)).To(Succeed())
+	})
+}
@@ -166,7 +166,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupAtomWebhookWithManager(mgr)
+	err = SetupAtomWebhookWithManager(mgr, &pdoknlv3.SettingsReader{}, false, false, false)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:webhook